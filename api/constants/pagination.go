@@ -0,0 +1,4 @@
+package constants
+
+var DefaultPageLimit int64 = 20
+var MaxPageLimit int64 = 100
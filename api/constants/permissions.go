@@ -0,0 +1,99 @@
+package constants
+
+import (
+	"fmt"
+	"strings"
+
+	types "auth_blog_service/types"
+)
+
+// Permissions is the registry of every permission roles may hold. Roles can
+// also use wildcards ("post.*", "*") and deny entries ("!post.delete.any").
+var Permissions = []types.Permission{
+	{Name: "role.read", Description: "List and read roles"},
+	{Name: "role.create", Description: "Create roles"},
+	{Name: "role.update", Description: "Update roles"},
+	{Name: "role.delete", Description: "Delete roles"},
+	{Name: "role.manage", Description: "Implies every role permission"},
+	{Name: "user.read", Description: "List and read users"},
+	{Name: "user.create", Description: "Create users"},
+	{Name: "user.update", Description: "Update any user"},
+	{Name: "user.delete", Description: "Delete users"},
+	{Name: "user.unlock", Description: "Clear login lockouts of a user"},
+	{Name: "user.impersonate", Description: "Act as another user with at most your own permissions"},
+	{Name: "user.manage", Description: "Implies every user permission"},
+	{Name: "post.create", Description: "Create posts as yourself"},
+	{Name: "post.create.any", Description: "Create posts on behalf of any user"},
+	{Name: "post.update.own", Description: "Update your own posts"},
+	{Name: "post.update.any", Description: "Update any post"},
+	{Name: "post.delete.own", Description: "Delete your own posts"},
+	{Name: "post.delete.any", Description: "Delete any post"},
+	{Name: "post.review", Description: "Approve or reject posts in review"},
+	{Name: "post.publish", Description: "Publish and archive posts, skipping review"},
+	{Name: "post.manage", Description: "Implies every post permission"},
+	{Name: "group.read", Description: "List and read groups"},
+	{Name: "group.create", Description: "Create groups"},
+	{Name: "group.update", Description: "Rename groups and manage their members"},
+	{Name: "group.delete", Description: "Delete groups"},
+	{Name: "group.manage", Description: "Implies every group permission"},
+	{Name: "audit.read", Description: "Read the impersonation audit trail"},
+	{Name: "authz.check", Description: "Ask whether any user may perform an action"},
+	{Name: "session.delete", Description: "Sign other users out"},
+	{Name: "session.manage", Description: "Implies every session permission"},
+	{Name: "client.read", Description: "List and read OAuth clients"},
+	{Name: "client.create", Description: "Register OAuth clients"},
+	{Name: "client.delete", Description: "Delete OAuth clients"},
+	{Name: "client.manage", Description: "Implies every OAuth client permission"},
+}
+
+// PermissionImplications lists permissions that grant others. Implied entries
+// may themselves be wildcards or imply further permissions.
+var PermissionImplications = map[string][]string{
+	"post.manage":    {"post.create", "post.create.*", "post.update.*", "post.delete.*", "post.review", "post.publish"},
+	"user.manage":    {"user.read", "user.create", "user.update", "user.delete", "user.unlock", "user.impersonate"},
+	"role.manage":    {"role.read", "role.create", "role.update", "role.delete"},
+	"group.manage":   {"group.read", "group.create", "group.update", "group.delete"},
+	"session.manage": {"session.delete"},
+	"client.manage":  {"client.read", "client.create", "client.delete"},
+}
+
+// IsRegisteredPermission reports whether name is in the registry.
+func IsRegisteredPermission(name string) bool {
+	for _, permission := range Permissions {
+		if permission.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePermissions rejects role entries that don't refer to a registered
+// permission. Deny entries and wildcards must match at least one of them.
+func ValidatePermissions(permissions []string) error {
+	for _, entry := range permissions {
+		name := strings.TrimPrefix(entry, "!")
+
+		if name == "*" || IsRegisteredPermission(name) {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".*") && matchesRegisteredPermission(strings.TrimSuffix(name, "*")) {
+			continue
+		}
+
+		return fmt.Errorf("Unknown permission %s", entry)
+	}
+
+	return nil
+}
+
+func matchesRegisteredPermission(prefix string) bool {
+	for _, permission := range Permissions {
+		if strings.HasPrefix(permission.Name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
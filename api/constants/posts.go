@@ -0,0 +1,22 @@
+package constants
+
+import types "auth_blog_service/types"
+
+var PostDraft string = "draft"
+var PostInReview string = "in_review"
+var PostPublished string = "published"
+var PostArchived string = "archived"
+
+// MaxPostSize bounds the title and body of a post together, in bytes, which
+// also bounds the revisions that get diffed.
+var MaxPostSize int = 100000
+
+// PostTransitions lists the moves of the post lifecycle, by endpoint name.
+var PostTransitions = map[string]types.PostTransition{
+	"submit":  {From: []string{PostDraft}, To: PostInReview, Permission: "post.update"},
+	"approve": {From: []string{PostInReview}, To: PostPublished, Permission: "post.review"},
+	"reject":  {From: []string{PostInReview}, To: PostDraft, Permission: "post.review"},
+	"publish": {From: []string{PostDraft, PostInReview, PostArchived}, To: PostPublished, Permission: "post.publish"},
+	"archive": {From: []string{PostPublished}, To: PostArchived, Permission: "post.publish"},
+	"reopen":  {From: []string{PostArchived}, To: PostDraft, Permission: "post.update"},
+}
@@ -7,5 +7,7 @@ var Unauthorized int = 401
 var Forbidden int = 403
 var NotFound int = 404
 var Conflict int = 409
+var RequestEntityTooLarge int = 413
 var UnprocessableEntity int = 422
+var TooManyRequests int = 429
 var InternalServerError int = 500
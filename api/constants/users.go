@@ -0,0 +1,4 @@
+package constants
+
+var UserPending string = "pending"
+var UserActive string = "active"
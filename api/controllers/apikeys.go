@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// authenticateKeyOwner returns the session user. Neither API keys nor
+// impersonation tokens can manage keys.
+func authenticateKeyOwner(connection *mongo.Database, w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+	if !auth {
+		helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+		return models.User{}, false
+	}
+
+	if !ownerOnly(connection, w, r, "API keys can only be managed by their owner") {
+		return models.User{}, false
+	}
+
+	return user, true
+}
+
+func GetMyAPIKeys(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authenticateKeyOwner(connection, w, r)
+
+		if !ok {
+			return
+		}
+
+		apiKeys, err, status := repositories.GetUserAPIKeys(connection, user.ID)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(apiKeys, w, status)
+	}
+}
+
+// CreateMyAPIKey issues a key limited to a subset of the caller's own
+// permissions. The plain key is only shown in this response.
+func CreateMyAPIKey(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authenticateKeyOwner(connection, w, r)
+
+		if !ok {
+			return
+		}
+
+		var apiKeyBody types.APIKeyBody
+
+		_ = json.NewDecoder(r.Body).Decode(&apiKeyBody)
+
+		granted := helpers.GetAuthorization(connection, r).GrantedPermissions()
+
+		if len(apiKeyBody.Permissions) != 0 && !helpers.HasPermissions(granted, apiKeyBody.Permissions) {
+			helpers.JSONError(fmt.Errorf("API key permissions exceed your own"), w, constants.Forbidden)
+			return
+		}
+
+		prefix, err := helpers.GenerateRandomToken(6)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		secret, err := helpers.GenerateRandomToken(32)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		prefix = "abs_" + prefix
+		key := prefix + "." + secret
+
+		apiKey := models.APIKey{
+			UserID:      user.ID,
+			Name:        apiKeyBody.Name,
+			Prefix:      prefix,
+			Hash:        helpers.HashToken(key),
+			Permissions: apiKeyBody.Permissions,
+			ExpiresDate: apiKeyBody.ExpiresDate.Time,
+		}
+
+		created, err, status := repositories.CreateAPIKey(connection, apiKey, key)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(created, w, status)
+	}
+}
+
+func RevokeMyAPIKey(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, ok := authenticateKeyOwner(connection, w, r)
+
+		if !ok {
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		revoked, err, status := repositories.RevokeAPIKey(connection, user.ID, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(revoked, w, status)
+	}
+}
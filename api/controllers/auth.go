@@ -3,6 +3,7 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -14,6 +15,20 @@ import (
 	types "auth_blog_service/types"
 )
 
+func loginAllowed(connection *mongo.Database, w http.ResponseWriter, keys []string) bool {
+	wait := helpers.CheckLoginAllowed(connection, keys)
+
+	if wait <= 0 {
+		return true
+	}
+
+	w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+
+	helpers.JSONError(fmt.Errorf("Too many failed attempts, try again later"), w, constants.TooManyRequests)
+
+	return false
+}
+
 func Login(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
@@ -27,28 +42,137 @@ func Login(connection *mongo.Database, permissions ...string) func(w http.Respon
 
 		_ = json.NewDecoder(r.Body).Decode(&tokenBody)
 
+		keys := helpers.LoginAttemptKeys(r, tokenBody.Username)
+
+		if !loginAllowed(connection, w, keys) {
+			return
+		}
+
 		user, err, _ := repositories.QueryUser(connection, bson.M{"username": tokenBody.Username})
 
 		if err != nil {
-			helpers.JSONError(fmt.Errorf("User don't exist"), w, constants.Unauthorized)
+			helpers.CheckDummyPassword(tokenBody.Password)
+		}
+
+		if err != nil || !helpers.CheckPasswordHash(tokenBody.Password, user.Password.Hash) {
+			helpers.RecordLoginFailure(connection, keys)
+
+			helpers.JSONError(fmt.Errorf("Invalid username or password"), w, constants.Unauthorized)
+			return
+		}
+
+		if user.Status == constants.UserPending {
+			helpers.JSONError(fmt.Errorf("Email verification is required"), w, constants.Forbidden)
+			return
+		}
+
+		if helpers.RequiresMFA(connection, user) {
+			mfaToken, err := helpers.CreateMFAToken(user)
+
+			if err != nil {
+				helpers.JSONError(fmt.Errorf("Could not login"), w, constants.Unauthorized)
+				return
+			}
+
+			challenge := types.MFAChallenge{
+				MFARequired:        true,
+				EnrollmentRequired: !user.MFAEnabled,
+				MFAToken:           mfaToken,
+			}
+
+			helpers.JSONSuccess(challenge, w, 200)
+			return
+		}
+
+		// Failures are only cleared once the whole login succeeded, otherwise
+		// re-entering the password would reset the count between MFA guesses.
+		helpers.ClearLoginFailures(connection, user.UserName)
+
+		tokens, err := helpers.IssueTokens(connection, r, user, "")
+
+		if err != nil {
+			helpers.JSONError(fmt.Errorf("Could not login"), w, constants.Unauthorized)
+			return
+		}
+
+		helpers.JSONSuccess(tokens, w, 200)
+	}
+}
+
+func LoginMFA(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var mfaBody types.MFABody
+
+		_ = json.NewDecoder(r.Body).Decode(&mfaBody)
+
+		user, err := helpers.GetMFATokenUser(connection, mfaBody.MFAToken)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.Unauthorized)
 			return
 		}
 
-		if !helpers.CheckPasswordHash(tokenBody.Password, user.Password.Hash) {
-			helpers.JSONError(fmt.Errorf("Wrong password"), w, constants.Unauthorized)
+		if !user.MFAEnabled {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication is not enrolled"), w, constants.Unauthorized)
+			return
+		}
+
+		keys := helpers.LoginAttemptKeys(r, user.UserName)
+
+		if !loginAllowed(connection, w, keys) {
+			return
+		}
+
+		err = helpers.VerifyMFA(connection, user, mfaBody.Code, mfaBody.RecoveryCode)
+
+		if err != nil {
+			helpers.RecordLoginFailure(connection, keys)
+
+			helpers.JSONError(err, w, constants.Unauthorized)
 			return
 		}
 
-		token, _ := helpers.CreateToken(user.UserName, user.RoleID.Hex())
+		helpers.ClearLoginFailures(connection, user.UserName)
 
-		_, err = repositories.StartSession(connection, token)
+		tokens, err := helpers.IssueTokens(connection, r, user, "")
 
 		if err != nil {
 			helpers.JSONError(fmt.Errorf("Could not login"), w, constants.Unauthorized)
 			return
 		}
 
-		helpers.JSONSuccess(token, w, 200)
+		helpers.JSONSuccess(tokens, w, 200)
+	}
+}
+
+func RefreshToken(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var refreshBody types.RefreshBody
+
+		_ = json.NewDecoder(r.Body).Decode(&refreshBody)
+
+		tokens, err := helpers.RefreshTokens(connection, r, refreshBody.RefreshToken, "")
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.Unauthorized)
+			return
+		}
+
+		helpers.JSONSuccess(tokens, w, 200)
 	}
 }
 
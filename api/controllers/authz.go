@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// CheckAuthorization lets other services ask whether a user may perform an
+// action on a resource, using the same policies and roles as this API.
+func CheckAuthorization(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var checkBody types.AuthzCheckBody
+
+		_ = json.NewDecoder(r.Body).Decode(&checkBody)
+
+		if checkBody.Action == "" {
+			helpers.JSONError(fmt.Errorf("Action is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		filter := bson.M{"username": checkBody.UserName}
+
+		if checkBody.UserID != "" {
+			id, _ := primitive.ObjectIDFromHex(checkBody.UserID)
+			filter = bson.M{"_id": id}
+		}
+
+		user, err, status := repositories.QueryUser(connection, filter)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		decision := helpers.CheckUserPermission(connection, user, checkBody.Action, checkBody.Resource, checkBody.Environment)
+
+		helpers.JSONSuccess(decision, w, constants.Success)
+	}
+}
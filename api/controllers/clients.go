@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+)
+
+func GetClients(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		clients, err, status := repositories.GetClients(connection)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(clients, w, status)
+	}
+}
+
+func CreateClient(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var client models.Client
+
+		_ = json.NewDecoder(r.Body).Decode(&client)
+
+		// A client acts with the roles of the user who registered it, so its
+		// scopes can't go beyond what that user holds.
+		scopes := helpers.ExcludeScopes(client.Scopes, helpers.OpenIDScopes)
+
+		if err := constants.ValidatePermissions(scopes); err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		if !helpers.HasPermissions(helpers.GetAuthorization(connection, r).GrantedPermissions(), scopes) {
+			helpers.JSONError(fmt.Errorf("Client scopes exceed your own"), w, constants.Forbidden)
+			return
+		}
+
+		client.OwnerID = user.ID
+
+		clientId, err := helpers.GenerateRandomToken(16)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		secret, err := helpers.GenerateRandomToken(32)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		created, err, status := repositories.CreateClient(connection, client, clientId, helpers.HashToken(secret))
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		// The secret is only stored hashed, so this is the one chance to see it.
+		if !created.Public {
+			created.ClientSecret = secret
+		}
+
+		helpers.JSONSuccess(created, w, status)
+	}
+}
+
+func GetClientById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		client, err, status := repositories.GetClient(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(client, w, status)
+	}
+}
+
+func DeleteClientById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		client, err, status := repositories.DeleteClient(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(client, w, status)
+	}
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+)
+
+func GetGroups(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		groups, err, status := repositories.GetGroups(connection)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(groups, w, status)
+	}
+}
+
+func CreateGroup(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var body models.Group
+
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		for _, member := range body.Members {
+			if auth, authErr := helpers.CanAssignGroupRole(connection, r, primitive.NilObjectID, member.RoleID); !auth {
+				helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Forbidden)
+				return
+			}
+		}
+
+		group, err, status := repositories.CreateGroup(connection, body)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+func GetGroupById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		group, err, status := repositories.GetGroup(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+// UpdateGroupById is also open to members whose group role has the
+// permissions, as are the member endpoints below.
+func UpdateGroupById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		auth, authErr := helpers.CheckGroupPermissions(connection, r, id, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		group, err, status := repositories.UpdateGroup(connection, params["id"], r.Body)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+func DeleteGroupById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		group, err, status := repositories.DeleteGroup(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+func SetGroupMemberById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		auth, authErr := helpers.CheckGroupPermissions(connection, r, id, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var member models.GroupMember
+
+		_ = json.NewDecoder(r.Body).Decode(&member)
+
+		if auth, authErr := helpers.CanAssignGroupRole(connection, r, id, member.RoleID); !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Forbidden)
+			return
+		}
+
+		if auth, authErr := helpers.CanManageGroupMember(connection, r, id, member.UserID); !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Forbidden)
+			return
+		}
+
+		group, err, status := repositories.SetGroupMember(connection, params["id"], member)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+func RemoveGroupMemberById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		auth, authErr := helpers.CheckGroupPermissions(connection, r, id, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		userId, _ := primitive.ObjectIDFromHex(params["userId"])
+
+		if auth, authErr := helpers.CanManageGroupMember(connection, r, id, userId); !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Forbidden)
+			return
+		}
+
+		group, err, status := repositories.RemoveGroupMember(connection, params["id"], params["userId"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(group, w, status)
+	}
+}
+
+func GetGroupPostsById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		posts, err, status := repositories.GetGroupPosts(connection, params["id"], helpers.PostVisibility(connection, r))
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(posts, w, status)
+	}
+}
@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+)
+
+func GetJWKS(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		ring, err := helpers.GetKeyRing()
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		helpers.JSONDocument(ring.JWKS(), w, constants.Success)
+	}
+}
@@ -0,0 +1,191 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	netmail "net/mail"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	mailer "auth_blog_service/mailer"
+	repositories "auth_blog_service/repositories"
+	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
+)
+
+func GetMe(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		helpers.JSONSuccess(serializers.SerializeOneUser(user), w, constants.Success)
+	}
+}
+
+// ownerOnly refuses requests made with an API key, an OAuth client token or
+// an impersonation token, which must not take over the account they act for.
+func ownerOnly(connection *mongo.Database, w http.ResponseWriter, r *http.Request, message string) bool {
+	if !helpers.GetAuthorization(connection, r).InPerson() {
+		helpers.JSONError(fmt.Errorf(message), w, constants.Forbidden)
+		return false
+	}
+
+	return true
+}
+
+// UpdateMe edits the caller's profile. The username can't be changed, and a
+// new email only replaces the current one once it has been confirmed.
+func UpdateMe(connection *mongo.Database, mail mailer.Mailer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		if !ownerOnly(connection, w, r, "The profile can only be changed by its owner") {
+			return
+		}
+
+		var profileBody types.ProfileBody
+
+		_ = json.NewDecoder(r.Body).Decode(&profileBody)
+
+		if profileBody.UserName != "" && profileBody.UserName != user.UserName {
+			helpers.JSONError(fmt.Errorf("User username can't be changed"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		changeEmail := profileBody.Email != "" && profileBody.Email != user.Email
+
+		if _, err := netmail.ParseAddress(profileBody.Email); changeEmail && err != nil {
+			helpers.JSONError(fmt.Errorf("Valid User email is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		updated, err, status := repositories.UpdateProfile(connection, user.ID, profileBody)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		if changeEmail {
+			err = sendEmailChange(connection, mail, user.ID, user.Name, profileBody.Email)
+
+			if err != nil {
+				helpers.JSONError(fmt.Errorf("Could not send the email confirmation"), w, constants.InternalServerError)
+				return
+			}
+		}
+
+		helpers.JSONSuccess(updated, w, status)
+	}
+}
+
+func UpdateMyPassword(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		if !ownerOnly(connection, w, r, "The password can only be changed by its owner") {
+			return
+		}
+
+		var passwordBody types.ChangePasswordBody
+
+		_ = json.NewDecoder(r.Body).Decode(&passwordBody)
+
+		if !helpers.CheckPasswordHash(passwordBody.CurrentPassword, user.Password.Hash) {
+			helpers.JSONError(fmt.Errorf("Current password is wrong"), w, constants.Forbidden)
+			return
+		}
+
+		if passwordBody.NewPassword == "" {
+			helpers.JSONError(fmt.Errorf("New password is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		hash, err := helpers.HashPassword(passwordBody.NewPassword)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		err = repositories.UpdateUserPassword(connection, user.ID, types.Password{Hash: hash})
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		repositories.StopOtherUserSessions(connection, user.ID, helpers.GetBearerToken(r))
+
+		helpers.JSONSuccess(nil, w, constants.Success)
+	}
+}
+
+func GetMyPosts(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		posts, page, err, status := repositories.ListUserPosts(connection, user.ID.Hex(), helpers.PostVisibility(connection, r), query)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONPage(posts, page, w, status)
+	}
+}
+
+// GetMyPermissions lists what the current token can actually do: the role
+// permissions, narrowed to the granted scopes for OAuth tokens.
+func GetMyPermissions(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		permissions := helpers.IntrospectToken(connection, helpers.GetBearerToken(r)).Permissions
+
+		if authorization := helpers.GetAuthorization(connection, r); authorization.APIKey != nil {
+			permissions = authorization.GrantedPermissions()
+		}
+
+		if permissions == nil {
+			permissions = []string{}
+		}
+
+		helpers.JSONSuccess(permissions, w, constants.Success)
+	}
+}
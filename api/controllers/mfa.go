@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+func EnrollMFA(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mfaBody types.MFABody
+
+		_ = json.NewDecoder(r.Body).Decode(&mfaBody)
+
+		user, auth, authErr := helpers.GetMFAUser(connection, r, mfaBody.MFAToken)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		if mfaBody.MFAToken == "" && !ownerOnly(connection, w, r, "Two-factor authentication can only be changed by its owner") {
+			return
+		}
+
+		if user.MFAEnabled {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication is already enabled"), w, constants.Conflict)
+			return
+		}
+
+		secret, err := helpers.GenerateTOTPSecret()
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		err = repositories.SetUserMFASecret(connection, user.ID, secret)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		enrollment := types.MFAEnrollment{
+			Secret: secret,
+			URI:    helpers.TOTPURI(helpers.GetMFAIssuer(), user.UserName, secret),
+		}
+
+		helpers.JSONSuccess(enrollment, w, constants.Success)
+	}
+}
+
+// ActivateMFA confirms the enrollment with a first code and hands out the
+// recovery codes. When the user enrolled from a login challenge, it also
+// completes that login.
+func ActivateMFA(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var mfaBody types.MFABody
+
+		_ = json.NewDecoder(r.Body).Decode(&mfaBody)
+
+		user, auth, authErr := helpers.GetMFAUser(connection, r, mfaBody.MFAToken)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		if mfaBody.MFAToken == "" && !ownerOnly(connection, w, r, "Two-factor authentication can only be changed by its owner") {
+			return
+		}
+
+		if user.MFAEnabled {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication is already enabled"), w, constants.Conflict)
+			return
+		}
+
+		if user.MFASecret == "" {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication enrollment is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		// Activating during login stands in for the second factor, so wrong
+		// codes count toward the same lockout as LoginMFA.
+		login := mfaBody.MFAToken != ""
+		keys := helpers.LoginAttemptKeys(r, user.UserName)
+
+		if login && !loginAllowed(connection, w, keys) {
+			return
+		}
+
+		step, ok := helpers.ValidateTOTP(user.MFASecret, mfaBody.Code, time.Now())
+
+		if !ok {
+			if login {
+				helpers.RecordLoginFailure(connection, keys)
+			}
+
+			helpers.JSONError(fmt.Errorf("Invalid code"), w, constants.Unauthorized)
+			return
+		}
+
+		err := repositories.UseUserMFAStep(connection, user.ID, step)
+
+		if err != nil {
+			if login {
+				helpers.RecordLoginFailure(connection, keys)
+			}
+
+			helpers.JSONError(err, w, constants.Unauthorized)
+			return
+		}
+
+		if login {
+			helpers.ClearLoginFailures(connection, user.UserName)
+		}
+
+		recoveryCodes, err := helpers.GenerateRecoveryCodes(helpers.RecoveryCodesCount)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		hashes := []string{}
+
+		for _, code := range recoveryCodes {
+			hashes = append(hashes, helpers.HashToken(code))
+		}
+
+		err = repositories.EnableUserMFA(connection, user.ID, hashes)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		activation := types.MFAActivation{
+			RecoveryCodes: recoveryCodes,
+		}
+
+		if login {
+			tokens, err := helpers.IssueTokens(connection, r, user, "")
+
+			if err != nil {
+				helpers.JSONError(fmt.Errorf("Could not login"), w, constants.Unauthorized)
+				return
+			}
+
+			activation.Tokens = &tokens
+		}
+
+		helpers.JSONSuccess(activation, w, constants.Success)
+	}
+}
+
+func DisableMFA(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		if !ownerOnly(connection, w, r, "Two-factor authentication can only be changed by its owner") {
+			return
+		}
+
+		if !user.MFAEnabled {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication is not enabled"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		if helpers.RoleRequiresMFA(connection, user) {
+			helpers.JSONError(fmt.Errorf("Two-factor authentication is required by your role"), w, constants.Forbidden)
+			return
+		}
+
+		var mfaBody types.MFABody
+
+		_ = json.NewDecoder(r.Body).Decode(&mfaBody)
+
+		err := helpers.VerifyMFA(connection, user, mfaBody.Code, mfaBody.RecoveryCode)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.Unauthorized)
+			return
+		}
+
+		err = repositories.DisableUserMFA(connection, user.ID)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		helpers.JSONSuccess(nil, w, constants.Success)
+	}
+}
@@ -0,0 +1,301 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+func oauthError(w http.ResponseWriter, status int, code string, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	helpers.JSONDocument(types.OAuthError{
+		Error:            code,
+		ErrorDescription: description,
+	}, w, status)
+}
+
+func oauthRedirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, _ := url.Parse(redirectURI)
+
+	query := target.Query()
+
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func oauthRedirectError(w http.ResponseWriter, r *http.Request, redirectURI string, state string, code string, description string) {
+	params := url.Values{}
+
+	params.Set("error", code)
+	params.Set("error_description", description)
+
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	oauthRedirect(w, r, redirectURI, params)
+}
+
+// Authorize implements the authorization endpoint for the authorization code
+// grant. The resource owner authenticates with a bearer token from
+// /api/login; the grant is limited to the scopes both the client and the
+// user's role allow.
+func Authorize(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		client, err, _ := repositories.QueryClient(connection, bson.M{"clientId": query.Get("client_id")})
+
+		if err != nil {
+			oauthError(w, constants.BadRequest, "invalid_client", "Unknown client")
+			return
+		}
+
+		redirectURI := query.Get("redirect_uri")
+
+		if redirectURI == "" && len(client.RedirectURIs) == 1 {
+			redirectURI = client.RedirectURIs[0]
+		}
+
+		if !helpers.Contains(client.RedirectURIs, redirectURI) {
+			oauthError(w, constants.BadRequest, "invalid_request", "Invalid redirect_uri")
+			return
+		}
+
+		state := query.Get("state")
+
+		if query.Get("response_type") != "code" {
+			oauthRedirectError(w, r, redirectURI, state, "unsupported_response_type", "Only the code response type is supported")
+			return
+		}
+
+		if !helpers.Contains(client.GrantTypes, "authorization_code") {
+			oauthRedirectError(w, r, redirectURI, state, "unauthorized_client", "Client can't use the authorization_code grant")
+			return
+		}
+
+		challenge := query.Get("code_challenge")
+		method := query.Get("code_challenge_method")
+
+		if client.Public && challenge == "" {
+			oauthRedirectError(w, r, redirectURI, state, "invalid_request", "PKCE code_challenge is required")
+			return
+		}
+
+		if method == "" {
+			method = "S256"
+		}
+
+		if method != "S256" && method != "plain" {
+			oauthRedirectError(w, r, redirectURI, state, "invalid_request", "Unsupported code_challenge_method")
+			return
+		}
+
+		// The plain method leaks the verifier to whoever sees the redirect,
+		// and public clients have no secret to fall back on.
+		if client.Public && method != "S256" {
+			oauthRedirectError(w, r, redirectURI, state, "invalid_request", "Public clients must use the S256 code_challenge_method")
+			return
+		}
+
+		requested := strings.Fields(query.Get("scope"))
+
+		if !helpers.ContainsSubSLice(requested, client.Scopes) {
+			oauthRedirectError(w, r, redirectURI, state, "invalid_scope", "Client is not allowed to request these scopes")
+			return
+		}
+
+		if _, err := helpers.GetOpenIDIssuer(); err != nil && helpers.Contains(requested, "openid") {
+			oauthRedirectError(w, r, redirectURI, state, "invalid_scope", "OpenID Connect is not available")
+			return
+		}
+
+		user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			oauthRedirectError(w, r, redirectURI, state, "access_denied", authErr.Error())
+			return
+		}
+
+		scopes, err := helpers.AuthorizationScopes(helpers.GetAuthorization(connection, r), requested)
+
+		if err != nil {
+			oauthRedirectError(w, r, redirectURI, state, "access_denied", err.Error())
+			return
+		}
+
+		code, err := helpers.GenerateRandomToken(32)
+
+		if err != nil {
+			oauthRedirectError(w, r, redirectURI, state, "server_error", "Could not create authorization code")
+			return
+		}
+
+		authorizationCode := models.AuthorizationCode{
+			UserID:              user.ID,
+			Hash:                helpers.HashToken(code),
+			ClientID:            client.ClientID,
+			RedirectURI:         redirectURI,
+			Scopes:              scopes,
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: method,
+			Nonce:               query.Get("nonce"),
+			ExpiresDate:         time.Now().Add(helpers.AuthorizationCodeDuration),
+		}
+
+		err = repositories.InsertAuthorizationCode(connection, authorizationCode)
+
+		if err != nil {
+			oauthRedirectError(w, r, redirectURI, state, "server_error", "Could not create authorization code")
+			return
+		}
+
+		params := url.Values{}
+
+		params.Set("code", code)
+
+		if state != "" {
+			params.Set("state", state)
+		}
+
+		oauthRedirect(w, r, redirectURI, params)
+	}
+}
+
+func Token(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			oauthError(w, constants.BadRequest, "invalid_request", "Invalid form body")
+			return
+		}
+
+		client, err := helpers.AuthenticateClient(connection, r)
+
+		if err != nil {
+			oauthError(w, constants.Unauthorized, "invalid_client", err.Error())
+			return
+		}
+
+		grantType := r.PostFormValue("grant_type")
+
+		if !helpers.Contains(client.GrantTypes, grantType) {
+			oauthError(w, constants.BadRequest, "unauthorized_client", "Client can't use this grant type")
+			return
+		}
+
+		var tokens types.TokenPair
+
+		switch grantType {
+		case "authorization_code":
+			code, err := repositories.UseAuthorizationCode(connection, helpers.HashToken(r.PostFormValue("code")))
+
+			if err != nil || code.ClientID != client.ClientID || time.Now().After(code.ExpiresDate) {
+				oauthError(w, constants.BadRequest, "invalid_grant", "Invalid authorization code")
+				return
+			}
+
+			if code.RedirectURI != r.PostFormValue("redirect_uri") {
+				oauthError(w, constants.BadRequest, "invalid_grant", "redirect_uri doesn't match")
+				return
+			}
+
+			if !helpers.VerifyCodeChallenge(r.PostFormValue("code_verifier"), code.CodeChallenge, code.CodeChallengeMethod) {
+				oauthError(w, constants.BadRequest, "invalid_grant", "Invalid code_verifier")
+				return
+			}
+
+			user, err, _ := repositories.QueryUser(connection, bson.M{"_id": code.UserID})
+
+			if err != nil {
+				oauthError(w, constants.BadRequest, "invalid_grant", "User don't exist")
+				return
+			}
+
+			tokens, err = helpers.IssueGrant(connection, r, helpers.Grant{
+				User:     user,
+				ClientID: client.ClientID,
+				Scopes:   code.Scopes,
+				Nonce:    code.Nonce,
+				Refresh:  helpers.Contains(client.GrantTypes, "refresh_token"),
+			})
+
+			if err != nil {
+				oauthError(w, constants.InternalServerError, "server_error", "Could not issue token")
+				return
+			}
+		case "refresh_token":
+			tokens, err = helpers.RefreshTokens(connection, r, r.PostFormValue("refresh_token"), client.ClientID)
+
+			if err != nil {
+				oauthError(w, constants.BadRequest, "invalid_grant", err.Error())
+				return
+			}
+		case "client_credentials":
+			if client.Public {
+				oauthError(w, constants.BadRequest, "unauthorized_client", "Public clients can't use client_credentials")
+				return
+			}
+
+			requested := strings.Fields(r.PostFormValue("scope"))
+
+			if len(requested) == 0 {
+				requested = client.Scopes
+			}
+
+			if !helpers.ContainsSubSLice(requested, client.Scopes) {
+				oauthError(w, constants.BadRequest, "invalid_scope", "Client is not allowed to request these scopes")
+				return
+			}
+
+			owner, err, _ := repositories.QueryUser(connection, bson.M{"_id": client.OwnerID})
+
+			if err != nil {
+				oauthError(w, constants.BadRequest, "unauthorized_client", "Client owner doesn't exist")
+				return
+			}
+
+			tokens, err = helpers.IssueGrant(connection, r, helpers.Grant{
+				ClientID: client.ClientID,
+				Scopes:   requested,
+				RoleIDs:  repositories.UserRoleIDs(owner),
+			})
+
+			if err != nil {
+				oauthError(w, constants.InternalServerError, "server_error", "Could not issue token")
+				return
+			}
+		default:
+			oauthError(w, constants.BadRequest, "unsupported_grant_type", "Unsupported grant type")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		helpers.JSONDocument(types.OAuthToken{
+			AccessToken:  tokens.AccessToken,
+			TokenType:    tokens.TokenType,
+			ExpiresIn:    tokens.ExpiresIn,
+			RefreshToken: tokens.RefreshToken,
+			Scope:        tokens.Scope,
+			IDToken:      tokens.IDToken,
+		}, w, constants.Success)
+	}
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+func GetOpenIDConfiguration(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		issuer, err := helpers.GetOpenIDIssuer()
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.NotFound)
+			return
+		}
+
+		ring, err := helpers.GetKeyRing()
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		helpers.JSONDocument(types.OpenIDConfiguration{
+			Issuer:                            issuer,
+			AuthorizationEndpoint:             issuer + "/oauth/authorize",
+			TokenEndpoint:                     issuer + "/oauth/token",
+			UserInfoEndpoint:                  issuer + "/userinfo",
+			JWKSURI:                           issuer + "/.well-known/jwks.json",
+			ResponseTypesSupported:            []string{"code"},
+			GrantTypesSupported:               repositories.GrantTypes,
+			SubjectTypesSupported:             []string{"public"},
+			IDTokenSigningAlgValuesSupported:  []string{ring.Signing.Method.Alg()},
+			ScopesSupported:                   helpers.OpenIDScopes,
+			ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "name", "preferred_username", "birthdate"},
+			CodeChallengeMethodsSupported:     []string{"S256", "plain"},
+			TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		}, w, constants.Success)
+	}
+}
+
+func UserInfo(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, auth, authErr := helpers.AuthenticateRequest(connection, r)
+
+		if !auth {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			oauthError(w, constants.Unauthorized, "invalid_token", authErr.Error())
+			return
+		}
+
+		claims, err := helpers.ExtractTokenClaims(session.Token)
+
+		if err != nil {
+			oauthError(w, constants.Unauthorized, "invalid_token", "Invalid token")
+			return
+		}
+
+		scopes, _ := helpers.GetTokenScopes(claims)
+
+		if !helpers.Contains(scopes, "openid") {
+			w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+			oauthError(w, constants.Forbidden, "insufficient_scope", "The openid scope is required")
+			return
+		}
+
+		user, err, _ := repositories.QueryUser(connection, bson.M{"username": helpers.GetClaimString(claims, "user_id")})
+
+		if err != nil {
+			oauthError(w, constants.Unauthorized, "invalid_token", "User don't exist")
+			return
+		}
+
+		helpers.JSONDocument(helpers.UserInfoClaims(user, scopes), w, constants.Success)
+	}
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	mailer "auth_blog_service/mailer"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+var PasswordResetDuration = time.Hour
+
+// ForgotPassword always answers the same way, whether or not the user
+// exists, so it can't be used to find out which accounts are registered.
+// The reset is sent in the background so the response time doesn't tell
+// either.
+func ForgotPassword(connection *mongo.Database, mail mailer.Mailer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var forgotBody types.ForgotPasswordBody
+
+		_ = json.NewDecoder(r.Body).Decode(&forgotBody)
+
+		filter := bson.M{"username": forgotBody.Username}
+
+		if forgotBody.Email != "" {
+			filter = bson.M{"email": forgotBody.Email}
+		}
+
+		user, err, _ := repositories.QueryUser(connection, filter)
+
+		if err == nil && user.Email != "" && (forgotBody.Username != "" || forgotBody.Email != "") {
+			go func() {
+				if err := sendPasswordReset(connection, mail, user); err != nil {
+					log.Println("Could not send password reset:", err)
+				}
+			}()
+		}
+
+		helpers.JSONSuccess("If the account exists, a reset link was sent to its email", w, constants.Success)
+	}
+}
+
+func sendPasswordReset(connection *mongo.Database, mail mailer.Mailer, user models.User) error {
+	token, err := helpers.GenerateRandomToken(32)
+
+	if err != nil {
+		return err
+	}
+
+	link, err := helpers.BuildTokenLink("PASSWORD_RESET_URL", "/reset-password", token)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = repositories.StartPasswordReset(connection, user.ID, helpers.HashToken(token), PasswordResetDuration)
+
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nUse the link below to choose a new password. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.",
+			user.Name,
+			int(PasswordResetDuration.Minutes()),
+			link,
+		),
+	})
+}
+
+func ResetPassword(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var resetBody types.ResetPasswordBody
+
+		_ = json.NewDecoder(r.Body).Decode(&resetBody)
+
+		if resetBody.Password == "" {
+			helpers.JSONError(fmt.Errorf("Password is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		reset, err := repositories.UsePasswordReset(connection, helpers.HashToken(resetBody.Token))
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.BadRequest)
+			return
+		}
+
+		hash, err := helpers.HashPassword(resetBody.Password)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		err = repositories.UpdateUserPassword(connection, reset.UserID, types.Password{Hash: hash})
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.NotFound)
+			return
+		}
+
+		repositories.StopUserSessions(connection, reset.UserID)
+
+		user, err, _ := repositories.QueryUser(connection, bson.M{"_id": reset.UserID})
+
+		if err == nil {
+			helpers.ClearLoginFailures(connection, user.UserName)
+		}
+
+		helpers.JSONSuccess(nil, w, constants.Success)
+	}
+}
@@ -1,15 +1,24 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
 
 	constants "auth_blog_service/constants"
 	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
 	repositories "auth_blog_service/repositories"
+	search "auth_blog_service/search"
+	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
 )
 
 func GetPosts(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
@@ -21,14 +30,57 @@ func GetPosts(connection *mongo.Database, permissions ...string) func(w http.Res
 			return
 		}
 
-		posts, err, status := repositories.GetPosts(connection)
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		posts, page, err, status := repositories.ListPosts(connection, helpers.PostVisibility(connection, r), query)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONPage(posts, page, w, status)
+	}
+}
+
+// SearchPosts ranks posts by relevance to the q parameter. Quoted phrases
+// and prefixes ending with "*" must match.
+func SearchPosts(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		backend, err := helpers.GetSearchBackend(connection)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		hits, err, status := repositories.SearchPosts(connection, backend, helpers.PostVisibility(connection, r), helpers.PostSearchFilter(connection, r), search.ParseQuery(r.URL.Query().Get("q")), int(query.Limit))
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
-		helpers.JSONSuccess(posts, w, status)
+		helpers.JSONSuccess(hits, w, status)
 	}
 }
 
@@ -41,13 +93,51 @@ func CreatePost(connection *mongo.Database, permissions ...string) func(w http.R
 			return
 		}
 
-		post, err, status := repositories.CreatePost(connection, r.Body)
+		var userId primitive.ObjectID
+		var body models.Post
+
+		raw, ok := readPostBody(w, r)
+
+		if !ok {
+			return
+		}
+
+		_ = json.Unmarshal(raw, &body)
+
+		if anyUser, _ := helpers.CheckPermissions(connection, r, []string{"post.create.any"}); !anyUser {
+			user, auth, authErr := helpers.GetAuthenticatedUser(connection, r)
+
+			if !auth {
+				helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+				return
+			}
+
+			userId = user.ID
+
+			if !body.GroupID.IsZero() {
+				auth, authErr := helpers.CheckGroupRolePermissions(connection, r, body.GroupID, []string{"post.create"})
+
+				if !auth {
+					helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+					return
+				}
+			}
+		} else if body.UserID.IsZero() {
+			// Without an explicit author the post is written as the caller.
+			if user, auth, _ := helpers.GetAuthenticatedUser(connection, r); auth {
+				userId = user.ID
+			}
+		}
+
+		post, err, status := repositories.CreatePost(connection, bytes.NewReader(raw), userId)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
+		helpers.IndexPost(connection, post)
+
 		helpers.JSONSuccess(post, w, status)
 	}
 }
@@ -63,51 +153,204 @@ func GetPostById(connection *mongo.Database, permissions ...string) func(w http.
 
 		var params = mux.Vars(r)
 
-		post, err, status := repositories.GetPost(connection, params["id"])
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		post, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil || !helpers.CanReadPost(connection, r, post) {
+			helpers.JSONError(fmt.Errorf("Requested Post doesn't exist"), w, constants.NotFound)
+			return
+		}
+
+		helpers.JSONSuccess(serializers.SerializeOnePost(post), w, status)
+	}
+}
+
+// UpdatePostById checks permissions against the post's author:
+// "post.update" is granted by "post.update.any", or by "post.update.own" for
+// the author and for members of the post's group.
+func UpdatePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		current, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
+		auth, authErr := helpers.CheckPostPermissions(connection, r, current, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		canReassign, _ := helpers.CheckPermissions(connection, r, []string{"post.update.any"})
+
+		canPublish, _ := helpers.CheckPostActionPermissions(connection, r, current, []string{"post.publish"})
+
+		editorId := helpers.GetAuthorization(connection, r).User.ID
+
+		raw, ok := readPostBody(w, r)
+
+		if !ok {
+			return
+		}
+
+		post, err, status := repositories.UpdatePost(connection, params["id"], bytes.NewReader(raw), canReassign, canPublish, editorId)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.IndexPost(connection, post)
+
 		helpers.JSONSuccess(post, w, status)
 	}
 }
 
-func UpdatePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+// DeletePostById checks permissions against the post's author:
+// "post.delete" is granted by "post.delete.any", or by "post.delete.own" for
+// the author and for members of the post's group.
+func DeletePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		current, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		auth, authErr := helpers.CheckPostPermissions(connection, r, current, permissions)
 
 		if !auth {
 			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
 			return
 		}
 
+		post, err, status := repositories.DeletePost(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.RemovePost(connection, params["id"])
+
+		helpers.JSONSuccess(post, w, status)
+	}
+}
+
+// TransitionPostById moves a post along its lifecycle, as listed in
+// constants.PostTransitions.
+func TransitionPostById(connection *mongo.Database, name string, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	transition := constants.PostTransitions[name]
+
+	return func(w http.ResponseWriter, r *http.Request) {
 		var params = mux.Vars(r)
 
-		post, err, status := repositories.UpdatePost(connection, params["id"], r.Body)
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		current, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		auth, authErr := helpers.CheckPostActionPermissions(connection, r, current, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		post, err, status := repositories.TransitionPost(connection, current.ID, transition)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
+		helpers.IndexPost(connection, post)
+
+		if transition.To == constants.PostPublished {
+			helpers.NotifyPostPublished(post)
+		}
+
 		helpers.JSONSuccess(post, w, status)
 	}
 }
 
-func DeletePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+// SchedulePostById sets when the scheduler publishes the post.
+func SchedulePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		current, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		auth, authErr := helpers.CheckPostActionPermissions(connection, r, current, permissions)
 
 		if !auth {
 			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
 			return
 		}
 
+		var scheduleBody types.ScheduleBody
+
+		if err := json.NewDecoder(r.Body).Decode(&scheduleBody); err != nil {
+			helpers.JSONError(fmt.Errorf("Valid Post publishAt is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		post, err, status := repositories.SchedulePost(connection, current.ID, scheduleBody.PublishAt)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(post, w, status)
+	}
+}
+
+func UnschedulePostById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
 		var params = mux.Vars(r)
 
-		post, err, status := repositories.DeletePost(connection, params["id"])
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		current, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		auth, authErr := helpers.CheckPostActionPermissions(connection, r, current, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		post, err, status := repositories.UnschedulePost(connection, current.ID)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
@@ -117,3 +360,16 @@ func DeletePostById(connection *mongo.Database, permissions ...string) func(w ht
 		helpers.JSONSuccess(post, w, status)
 	}
 }
+
+// readPostBody reads a post request, leaving room for the JSON around a post
+// of MaxPostSize.
+func readPostBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	raw, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, int64(2*constants.MaxPostSize)))
+
+	if err != nil {
+		helpers.JSONError(fmt.Errorf("Post is too large"), w, constants.RequestEntityTooLarge)
+		return nil, false
+	}
+
+	return raw, true
+}
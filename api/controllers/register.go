@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	mailer "auth_blog_service/mailer"
+	repositories "auth_blog_service/repositories"
+	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
+)
+
+var EmailVerificationDuration = time.Hour * 24
+
+func registrationEnabled(w http.ResponseWriter) bool {
+	if os.Getenv("REGISTRATION_ENABLED") == "true" {
+		return true
+	}
+
+	helpers.JSONError(fmt.Errorf("Registration is disabled"), w, constants.NotFound)
+
+	return false
+}
+
+// Register answers the same way whether or not the email is already taken;
+// the owner of an existing account is told about the attempt by email instead.
+func Register(connection *mongo.Database, mail mailer.Mailer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !registrationEnabled(w) {
+			return
+		}
+
+		role, err, _ := repositories.QueryRole(connection, bson.M{"name": "User"})
+
+		if err != nil {
+			helpers.JSONError(fmt.Errorf("Default Role doesn't exists"), w, constants.InternalServerError)
+			return
+		}
+
+		user, err, status := repositories.RegisterUser(connection, r.Body, role.ID)
+
+		if err == repositories.ErrEmailRegistered {
+			err = sendRegistrationNotice(connection, mail, user)
+		} else if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		} else {
+			err = sendEmailVerification(connection, mail, user.ID, user.Name, user.Email)
+		}
+
+		if err != nil {
+			fmt.Println("Could not send email verification:", err)
+		}
+
+		helpers.JSONSuccess("If the email can be registered, a verification link was sent to it", w, constants.Success)
+	}
+}
+
+// sendRegistrationNotice tells the owner of an existing account that someone
+// tried to register with their email. Pending accounts get a new verification
+// link, since that is most likely what they were after.
+func sendRegistrationNotice(connection *mongo.Database, mail mailer.Mailer, user serializers.User) error {
+	if user.Status == constants.UserPending {
+		return sendEmailVerification(connection, mail, user.ID, user.Name, user.Email)
+	}
+
+	return mail.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your account already exists",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone tried to create a new account with this email, which is already registered as %s.\n\nIf that was you, sign in or reset your password instead. Otherwise you can ignore this email.",
+			user.Name,
+			user.UserName,
+		),
+	})
+}
+
+func VerifyEmail(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+
+		if token == "" {
+			var tokenBody types.TokenBody
+
+			_ = json.NewDecoder(r.Body).Decode(&tokenBody)
+
+			token = tokenBody.Token
+		}
+
+		verification, err := repositories.UseEmailVerification(connection, helpers.HashToken(token))
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.BadRequest)
+			return
+		}
+
+		err, status := repositories.ConfirmEmail(connection, verification)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		user, err, status := repositories.GetUser(connection, verification.UserID.Hex())
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(user, w, status)
+	}
+}
+
+// ResendEmailVerification answers the same way for unknown, verified and
+// pending accounts so it can't be used to probe for registered emails.
+func ResendEmailVerification(connection *mongo.Database, mail mailer.Mailer) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !registrationEnabled(w) {
+			return
+		}
+
+		var emailBody types.EmailBody
+
+		_ = json.NewDecoder(r.Body).Decode(&emailBody)
+
+		user, err, _ := repositories.QueryUser(connection, bson.M{"email": emailBody.Email})
+
+		if err == nil && emailBody.Email != "" && user.Status == constants.UserPending {
+			err = sendEmailVerification(connection, mail, user.ID, user.Name, user.Email)
+
+			if err != nil {
+				fmt.Println("Could not send email verification:", err)
+			}
+		}
+
+		helpers.JSONSuccess("If the account is pending, a verification link was sent to its email", w, constants.Success)
+	}
+}
+
+func sendEmailVerification(connection *mongo.Database, mail mailer.Mailer, userId primitive.ObjectID, name string, email string) error {
+	link, err := startEmailVerification(connection, userId, email)
+
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nConfirm your email to activate your account:\n\n%s\n\nThe link expires in %d hours.",
+			name,
+			link,
+			int(EmailVerificationDuration.Hours()),
+		),
+	})
+}
+
+// sendEmailChange asks the user to confirm a new email address. The account
+// keeps its current email until the link is used.
+func sendEmailChange(connection *mongo.Database, mail mailer.Mailer, userId primitive.ObjectID, name string, email string) error {
+	link, err := startEmailVerification(connection, userId, email)
+
+	if err != nil {
+		return err
+	}
+
+	return mail.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirm your new email",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nConfirm this address to use it for your account:\n\n%s\n\nThe link expires in %d hours. If you didn't ask for this, you can ignore this email.",
+			name,
+			link,
+			int(EmailVerificationDuration.Hours()),
+		),
+	})
+}
+
+func startEmailVerification(connection *mongo.Database, userId primitive.ObjectID, email string) (string, error) {
+	token, err := helpers.GenerateRandomToken(32)
+
+	if err != nil {
+		return "", err
+	}
+
+	link, err := helpers.BuildTokenLink("EMAIL_VERIFICATION_URL", "/api/register/verify", token)
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = repositories.StartEmailVerification(connection, userId, email, helpers.HashToken(token), EmailVerificationDuration)
+
+	if err != nil {
+		return "", err
+	}
+
+	return link, nil
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	serializers "auth_blog_service/serializers"
+)
+
+// authorizePostRevisions loads the post of the URL and checks permissions
+// against it. Revisions hold past content, so they're limited to editors.
+func authorizePostRevisions(connection *mongo.Database, w http.ResponseWriter, r *http.Request, permissions []string) (models.Post, bool) {
+	var params = mux.Vars(r)
+
+	id, _ := primitive.ObjectIDFromHex(params["id"])
+
+	post, err, status := repositories.QueryPost(connection, bson.M{"_id": id})
+
+	if err != nil {
+		helpers.JSONError(err, w, status)
+		return models.Post{}, false
+	}
+
+	auth, authErr := helpers.CheckPostPermissions(connection, r, post, permissions)
+
+	if !auth {
+		helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+		return models.Post{}, false
+	}
+
+	return post, true
+}
+
+func GetPostRevisionsById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := authorizePostRevisions(connection, w, r, permissions)
+
+		if !ok {
+			return
+		}
+
+		revisions, err, status := repositories.GetPostRevisions(connection, post.ID)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(revisions, w, status)
+	}
+}
+
+func GetPostRevisionById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := authorizePostRevisions(connection, w, r, permissions)
+
+		if !ok {
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		revision, err, status := repositories.GetPostRevision(connection, post.ID, params["number"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(serializers.SerializeOnePostRevision(revision), w, status)
+	}
+}
+
+// DiffPostRevisionsById compares the revisions given as the from and to
+// parameters. to defaults to the current revision.
+func DiffPostRevisionsById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		post, ok := authorizePostRevisions(connection, w, r, permissions)
+
+		if !ok {
+			return
+		}
+
+		from := r.URL.Query().Get("from")
+		to := r.URL.Query().Get("to")
+
+		if from == "" {
+			helpers.JSONError(fmt.Errorf("from is required"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		if to == "" {
+			to = fmt.Sprint(post.Revision)
+		}
+
+		revisionDiff, err, status := repositories.DiffPostRevisions(connection, post.ID, from, to)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(revisionDiff, w, status)
+	}
+}
+
+func RestorePostRevisionById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		current, ok := authorizePostRevisions(connection, w, r, permissions)
+
+		if !ok {
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		canPublish, _ := helpers.CheckPostActionPermissions(connection, r, current, []string{"post.publish"})
+
+		editorId := helpers.GetAuthorization(connection, r).User.ID
+
+		post, err, status := repositories.RestorePostRevision(connection, current, params["number"], canPublish, editorId)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.IndexPost(connection, post)
+
+		helpers.JSONSuccess(post, w, status)
+	}
+}
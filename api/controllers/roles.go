@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
 
 	constants "auth_blog_service/constants"
 	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
 	repositories "auth_blog_service/repositories"
 )
 
@@ -21,14 +27,21 @@ func GetRoles(connection *mongo.Database, permissions ...string) func(w http.Res
 			return
 		}
 
-		roles, err, status := repositories.GetRoles(connection)
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		roles, page, err, status := repositories.ListRoles(connection, query)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
-		helpers.JSONSuccess(roles, w, status)
+		helpers.JSONPage(roles, page, w, status)
 	}
 }
 
@@ -85,7 +98,34 @@ func UpdateRoleById(connection *mongo.Database, permissions ...string) func(w ht
 
 		var params = mux.Vars(r)
 
-		role, err, status := repositories.UpdateRole(connection, params["id"], r.Body)
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		if current, err, _ := repositories.QueryRole(connection, bson.M{"_id": id}); err == nil {
+			var changes models.Role
+
+			_ = json.Unmarshal(raw, &changes)
+
+			if changes.Permissions != nil {
+				current.Permissions = changes.Permissions
+			}
+
+			if changes.ParentIDs != nil {
+				current.ParentIDs = changes.ParentIDs
+			}
+
+			err = helpers.CheckAdminRemains(connection, helpers.AdminChange{
+				Roles: map[primitive.ObjectID]*models.Role{id: &current},
+			})
+
+			if err != nil {
+				helpers.JSONError(err, w, constants.Conflict)
+				return
+			}
+		}
+
+		role, err, status := repositories.UpdateRole(connection, params["id"], bytes.NewReader(raw))
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
@@ -107,7 +147,25 @@ func DeleteRoleById(connection *mongo.Database, permissions ...string) func(w ht
 
 		var params = mux.Vars(r)
 
-		role, err, status := repositories.DeleteRole(connection, params["id"])
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+		reassignTo := r.URL.Query().Get("reassignTo")
+
+		change := helpers.AdminChange{
+			Roles: map[primitive.ObjectID]*models.Role{id: nil},
+		}
+
+		if reassignId, err := primitive.ObjectIDFromHex(reassignTo); err == nil {
+			change.Reassign = map[primitive.ObjectID]primitive.ObjectID{id: reassignId}
+		}
+
+		err := helpers.CheckAdminRemains(connection, change)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.Conflict)
+			return
+		}
+
+		role, err, status := repositories.DeleteRole(connection, params["id"], reassignTo)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
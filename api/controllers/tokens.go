@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	helpers "auth_blog_service/helpers"
+	repositories "auth_blog_service/repositories"
+)
+
+func IntrospectToken(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			oauthError(w, constants.BadRequest, "invalid_request", "Invalid form body")
+			return
+		}
+
+		client, err := helpers.AuthenticateClient(connection, r)
+
+		if err != nil || client.Public {
+			oauthError(w, constants.Unauthorized, "invalid_client", "Client authentication is required")
+			return
+		}
+
+		token := r.PostFormValue("token")
+
+		if token == "" {
+			oauthError(w, constants.BadRequest, "invalid_request", "token is required")
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+
+		helpers.JSONDocument(helpers.IntrospectToken(connection, token), w, constants.Success)
+	}
+}
+
+// RevokeToken ends the session of an access token, or the whole token family
+// of a refresh token. Unknown tokens are not an error, as RFC 7009 requires.
+func RevokeToken(connection *mongo.Database) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			oauthError(w, constants.BadRequest, "invalid_request", "Invalid form body")
+			return
+		}
+
+		client, err := helpers.AuthenticateClient(connection, r)
+
+		if err != nil {
+			oauthError(w, constants.Unauthorized, "invalid_client", "Client authentication is required")
+			return
+		}
+
+		token := r.PostFormValue("token")
+
+		if token == "" {
+			oauthError(w, constants.BadRequest, "invalid_request", "token is required")
+			return
+		}
+
+		if session, err := repositories.GetSession(connection, token); err == nil {
+			if session.ClientID != client.ClientID {
+				oauthError(w, constants.Forbidden, "unauthorized_client", "Token was not issued to this client")
+				return
+			}
+
+			err = repositories.StopSession(connection, token)
+
+			if err != nil {
+				oauthError(w, constants.InternalServerError, "server_error", "Could not revoke token")
+				return
+			}
+		} else if refreshToken, err := repositories.GetRefreshToken(connection, helpers.HashToken(token)); err == nil {
+			if refreshToken.ClientID != client.ClientID {
+				oauthError(w, constants.Forbidden, "unauthorized_client", "Token was not issued to this client")
+				return
+			}
+
+			err = repositories.RevokeTokenFamily(connection, refreshToken.Family)
+
+			if err != nil {
+				oauthError(w, constants.InternalServerError, "server_error", "Could not revoke token")
+				return
+			}
+		}
+
+		helpers.JSONSuccess(nil, w, constants.Success)
+	}
+}
@@ -1,14 +1,20 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
 
 	constants "auth_blog_service/constants"
 	helpers "auth_blog_service/helpers"
+	"auth_blog_service/models"
 	repositories "auth_blog_service/repositories"
 )
 
@@ -21,14 +27,21 @@ func GetUsers(connection *mongo.Database, permissions ...string) func(w http.Res
 			return
 		}
 
-		posts, err, status := repositories.GetUsers(connection)
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		users, page, err, status := repositories.ListUsers(connection, query)
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
-		helpers.JSONSuccess(posts, w, status)
+		helpers.JSONPage(users, page, w, status)
 	}
 }
 
@@ -96,6 +109,28 @@ func GetUserRoleById(connection *mongo.Database, permissions ...string) func(w h
 	}
 }
 
+func GetUserRolesById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		roles, err, status := repositories.GetUserRoles(connection, params["id"])
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(roles, w, status)
+	}
+}
+
 func GetUserPostsById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
@@ -107,14 +142,50 @@ func GetUserPostsById(connection *mongo.Database, permissions ...string) func(w
 
 		var params = mux.Vars(r)
 
-		posts, err, status := repositories.GetUserPosts(connection, params["id"])
+		query, err := helpers.ParseListQuery(r)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.UnprocessableEntity)
+			return
+		}
+
+		posts, page, err, status := repositories.ListUserPosts(connection, params["id"], helpers.PostVisibility(connection, r), query)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONPage(posts, page, w, status)
+	}
+}
+
+func UnlockUserById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		user, err, status := repositories.GetUser(connection, params["id"])
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
 			return
 		}
 
-		helpers.JSONSuccess(posts, w, status)
+		err = helpers.ClearLoginFailures(connection, user.UserName)
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		helpers.JSONSuccess(user, w, status)
 	}
 }
 
@@ -129,7 +200,33 @@ func UpdateUserById(connection *mongo.Database, permissions ...string) func(w ht
 
 		var params = mux.Vars(r)
 
-		user, err, status := repositories.UpdateUser(connection, params["id"], r.Body)
+		raw, _ := ioutil.ReadAll(r.Body)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		var changes models.User
+
+		_ = json.Unmarshal(raw, &changes)
+
+		if helpers.GetAuthorization(connection, r).Impersonating() && (changes.Password.Hash != "" || !changes.RoleID.IsZero() || changes.RoleIDs != nil) {
+			helpers.JSONError(fmt.Errorf("Password and role changes are not allowed during impersonation"), w, constants.Forbidden)
+			return
+		}
+
+		if current, err, _ := repositories.QueryUser(connection, bson.M{"_id": id}); err == nil && (!changes.RoleID.IsZero() || changes.RoleIDs != nil) {
+			_, roleIds := repositories.MergeUserRoles(current.RoleID, repositories.UserRoleIDs(current), changes.RoleID, changes.RoleIDs)
+
+			err = helpers.CheckAdminRemains(connection, helpers.AdminChange{
+				Users: map[primitive.ObjectID][]primitive.ObjectID{id: roleIds},
+			})
+
+			if err != nil {
+				helpers.JSONError(err, w, constants.Conflict)
+				return
+			}
+		}
+
+		user, err, status := repositories.UpdateUser(connection, params["id"], bytes.NewReader(raw))
 
 		if err != nil {
 			helpers.JSONError(err, w, status)
@@ -151,6 +248,17 @@ func DeleteUserById(connection *mongo.Database, permissions ...string) func(w ht
 
 		var params = mux.Vars(r)
 
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		err := helpers.CheckAdminRemains(connection, helpers.AdminChange{
+			Users: map[primitive.ObjectID][]primitive.ObjectID{id: nil},
+		})
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.Conflict)
+			return
+		}
+
 		user, err, status := repositories.DeleteUser(connection, params["id"])
 
 		if err != nil {
@@ -161,3 +269,91 @@ func DeleteUserById(connection *mongo.Database, permissions ...string) func(w ht
 		helpers.JSONSuccess(user, w, status)
 	}
 }
+
+// ImpersonateUserById issues a short-lived token acting as the user. The
+// token can't be refreshed and every request made with it is audited.
+func ImpersonateUserById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		authorization := helpers.GetAuthorization(connection, r)
+
+		if authorization.User.ID.IsZero() || authorization.APIKey != nil {
+			helpers.JSONError(fmt.Errorf("Only users signed in can impersonate"), w, constants.Forbidden)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		user, err, _ := repositories.QueryUser(connection, bson.M{"_id": id})
+
+		if err != nil {
+			helpers.JSONError(fmt.Errorf("Requested User doesn't exist"), w, constants.NotFound)
+			return
+		}
+
+		if user.ID == authorization.User.ID {
+			helpers.JSONError(fmt.Errorf("You can't impersonate yourself"), w, constants.UnprocessableEntity)
+			return
+		}
+
+		roles, _ := repositories.ResolveRoles(connection, repositories.UserRoleIDs(user))
+
+		if !helpers.CanImpersonate(authorization.GrantedPermissions(), repositories.EffectivePermissions(roles)) {
+			helpers.JSONError(fmt.Errorf("Requested User has permissions you don't have"), w, constants.Forbidden)
+			return
+		}
+
+		tokens, err := helpers.IssueGrant(connection, r, helpers.Grant{
+			User:         user,
+			Impersonator: authorization.User,
+		})
+
+		if err != nil {
+			helpers.JSONError(err, w, constants.InternalServerError)
+			return
+		}
+
+		repositories.InsertAuditRecord(connection, models.AuditRecord{
+			ImpersonatorID: authorization.User.ID,
+			UserID:         user.ID,
+			Method:         r.Method,
+			Path:           r.URL.RequestURI(),
+			Status:         constants.Success,
+			IP:             helpers.GetClientIP(r),
+		})
+
+		helpers.JSONSuccess(tokens, w, constants.Success)
+	}
+}
+
+func GetUserImpersonationsById(connection *mongo.Database, permissions ...string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		auth, authErr := helpers.CheckPermissions(connection, r, permissions)
+
+		if !auth {
+			helpers.JSONError(fmt.Errorf(authErr.Error()), w, constants.Unauthorized)
+			return
+		}
+
+		var params = mux.Vars(r)
+
+		id, _ := primitive.ObjectIDFromHex(params["id"])
+
+		records, err, status := repositories.GetImpersonationRecords(connection, id)
+
+		if err != nil {
+			helpers.JSONError(err, w, status)
+			return
+		}
+
+		helpers.JSONSuccess(records, w, status)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gopkg.in/mgo.v2/bson"
 
+	constants "auth_blog_service/constants"
 	helpers "auth_blog_service/helpers"
 	"auth_blog_service/models"
 	repositories "auth_blog_service/repositories"
@@ -26,23 +28,17 @@ func Seed(connection *mongo.Database) {
 
 		roles := []models.Role{
 			{
-				Name:        "User",
-				Permissions: []string{},
-			},
-			{
-				Name: "Admin",
+				Name: "User",
 				Permissions: []string{
-					"role.update",
-					"role.create",
-					"role.delete",
-					"user.update",
-					"user.create",
-					"user.delete",
-					"post.update",
 					"post.create",
-					"post.delete",
+					"post.update.own",
+					"post.delete.own",
 				},
 			},
+			{
+				Name:        "Admin",
+				Permissions: []string{"*"},
+			},
 		}
 
 		for _, role := range roles {
@@ -64,6 +60,7 @@ func Seed(connection *mongo.Database) {
 		users := []models.User{
 			{
 				RoleID:   userRole[0].ID,
+				RoleIDs:  []primitive.ObjectID{userRole[0].ID},
 				UserName: "user",
 				Name:     "Test",
 				Password: types.Password{
@@ -75,6 +72,7 @@ func Seed(connection *mongo.Database) {
 			},
 			{
 				RoleID:   adminRole[0].ID,
+				RoleIDs:  []primitive.ObjectID{adminRole[0].ID},
 				UserName: "admin",
 				Name:     "Admin Test",
 				Password: types.Password{
@@ -103,6 +101,7 @@ func Seed(connection *mongo.Database) {
 				UserID: user[0].ID,
 				Title:  "Testing post 01",
 				Body:   "Test body with some changes\nline",
+				Status: constants.PostPublished,
 				CreatedDate: types.Datetime{
 					Time: time.Now(),
 				},
@@ -111,6 +110,7 @@ func Seed(connection *mongo.Database) {
 				UserID: user[0].ID,
 				Title:  "Testing post 02",
 				Body:   "Test body with some changes\nline",
+				Status: constants.PostPublished,
 				CreatedDate: types.Datetime{
 					Time: time.Now(),
 				},
@@ -0,0 +1,230 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Context is the number of unchanged lines shown around each change.
+const Context = 3
+
+type operation struct {
+	Kind byte
+	Line string
+}
+
+// Unified renders the line diff from one text to another in unified format.
+// Identical texts give an empty string.
+func Unified(fromName string, toName string, from string, to string) string {
+	operations := editScript(strings.Split(from, "\n"), strings.Split(to, "\n"))
+
+	hunks := renderHunks(operations)
+
+	if hunks == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("--- %s\n+++ %s\n%s", fromName, toName, hunks)
+}
+
+// MaxLines and MaxEditDistance bound the work of editScript. Texts past
+// either limit are diffed as a whole replacement instead.
+const (
+	MaxLines        = 10000
+	MaxEditDistance = 1000
+)
+
+// editScript finds a shortest edit script with Myers' algorithm. Step d only
+// touches diagonals -d-1 to d+1, so only that part of V is kept for the
+// backtrack, and memory stays O(D²).
+func editScript(a []string, b []string) []operation {
+	n, m := len(a), len(b)
+
+	if n > MaxLines || m > MaxLines {
+		return replacement(a, b)
+	}
+
+	max := n + m
+	offset := max + 1
+
+	v := make([]int, 2*max+3)
+	trace := [][]int{}
+
+	for d := 0; d <= max && d <= MaxEditDistance; d++ {
+		trace = append(trace, append([]int{}, v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+
+	return replacement(a, b)
+}
+
+// replacement removes every line of a and adds every line of b.
+func replacement(a []string, b []string) []operation {
+	operations := make([]operation, 0, len(a)+len(b))
+
+	for _, line := range a {
+		operations = append(operations, operation{Kind: '-', Line: line})
+	}
+
+	for _, line := range b {
+		operations = append(operations, operation{Kind: '+', Line: line})
+	}
+
+	return operations
+}
+
+// backtrack walks the trace back from the end. trace[d] holds V for
+// diagonals -d-1 to d+1 as it was before step d.
+func backtrack(a []string, b []string, trace [][]int) []operation {
+	operations := []operation{}
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		var previousK int
+
+		if k == -d || k != d && v[k-1+d+1] < v[k+1+d+1] {
+			previousK = k + 1
+		} else {
+			previousK = k - 1
+		}
+
+		previousX := v[previousK+d+1]
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			operations = append(operations, operation{Kind: ' ', Line: a[x-1]})
+			x--
+			y--
+		}
+
+		if d > 0 {
+			if x == previousX {
+				operations = append(operations, operation{Kind: '+', Line: b[y-1]})
+			} else {
+				operations = append(operations, operation{Kind: '-', Line: a[x-1]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	for i, j := 0, len(operations)-1; i < j; i, j = i+1, j-1 {
+		operations[i], operations[j] = operations[j], operations[i]
+	}
+
+	return operations
+}
+
+// renderHunks groups changes closer than twice the context into hunks.
+func renderHunks(operations []operation) string {
+	var builder strings.Builder
+
+	for start := 0; start < len(operations); {
+		first := nextChange(operations, start)
+
+		if first < 0 {
+			break
+		}
+
+		last := first
+
+		for next := nextChange(operations, last+1); next >= 0 && next-last <= 2*Context; next = nextChange(operations, last+1) {
+			last = next
+		}
+
+		from := first - Context
+
+		if from < start {
+			from = start
+		}
+
+		to := last + Context + 1
+
+		if to > len(operations) {
+			to = len(operations)
+		}
+
+		writeHunk(&builder, operations, from, to)
+
+		start = to
+	}
+
+	return builder.String()
+}
+
+func nextChange(operations []operation, from int) int {
+	for i := from; i < len(operations); i++ {
+		if operations[i].Kind != ' ' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func writeHunk(builder *strings.Builder, operations []operation, from int, to int) {
+	aLine, bLine := 1, 1
+
+	for _, operation := range operations[:from] {
+		if operation.Kind != '+' {
+			aLine++
+		}
+
+		if operation.Kind != '-' {
+			bLine++
+		}
+	}
+
+	aCount, bCount := 0, 0
+
+	for _, operation := range operations[from:to] {
+		if operation.Kind != '+' {
+			aCount++
+		}
+
+		if operation.Kind != '-' {
+			bCount++
+		}
+	}
+
+	if aCount == 0 {
+		aLine--
+	}
+
+	if bCount == 0 {
+		bLine--
+	}
+
+	fmt.Fprintf(builder, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+
+	for _, operation := range operations[from:to] {
+		builder.WriteByte(operation.Kind)
+		builder.WriteString(operation.Line)
+		builder.WriteByte('\n')
+	}
+}
@@ -0,0 +1,77 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	if Unified("a", "b", "same\ntext", "same\ntext") == "" {
+		t.Log("Unified 01 passed")
+	} else {
+		t.Error("Unified 01 failed")
+	}
+
+	expected := "--- a\n+++ b\n@@ -1,3 +1,3 @@\n one\n-two\n+2\n three\n"
+
+	if got := Unified("a", "b", "one\ntwo\nthree", "one\n2\nthree"); got == expected {
+		t.Log("Unified 02 passed")
+	} else {
+		t.Errorf("Unified 02 failed: %q", got)
+	}
+
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12"
+	to := "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11"
+	expected = "--- a\n+++ b\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n"
+
+	if got := Unified("a", "b", from, to); got == expected {
+		t.Log("Unified 03 passed")
+	} else {
+		t.Errorf("Unified 03 failed: %q", got)
+	}
+
+	if got := Unified("a", "b", "", "new"); got == "--- a\n+++ b\n@@ -1,1 +1,1 @@\n-\n+new\n" {
+		t.Log("Unified 04 passed")
+	} else {
+		t.Errorf("Unified 04 failed: %q", got)
+	}
+}
+
+func TestEditScriptLimits(t *testing.T) {
+	from, to := []string{}, []string{}
+
+	for i := 0; i < MaxEditDistance; i++ {
+		from = append(from, "a"+strconv.Itoa(i))
+		to = append(to, "b"+strconv.Itoa(i))
+	}
+
+	from = append(from, "same")
+	to = append(to, "same")
+
+	operations := editScript(from, to)
+
+	if len(operations) == 2*len(from) && operations[len(from)-1].Kind == '-' && operations[len(operations)-1].Line == "same" {
+		t.Log("EditScriptLimits 01 passed")
+	} else {
+		t.Error("EditScriptLimits 01 failed")
+	}
+
+	long := strings.Split(strings.Repeat("line\n", MaxLines), "\n")
+
+	operations = editScript(long, long)
+
+	if len(operations) == 2*len(long) && operations[0].Kind == '-' {
+		t.Log("EditScriptLimits 02 passed")
+	} else {
+		t.Error("EditScriptLimits 02 failed")
+	}
+
+	operations = editScript(append(from[:10:10], "x"), append(from[:10:10], "y"))
+
+	if len(operations) == 12 && operations[10].Kind == '-' && operations[11].Kind == '+' {
+		t.Log("EditScriptLimits 03 passed")
+	} else {
+		t.Error("EditScriptLimits 03 failed")
+	}
+}
@@ -0,0 +1,136 @@
+package helpers
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+)
+
+// AdminPermissions are what lets a user manage roles, and so grant anything
+// back. At least one active user must keep them.
+var AdminPermissions = []string{"role.update"}
+
+// AdminChange describes a pending change to users or roles. A nil entry in
+// Users or Roles removes that user or role; Reassign moves every user of a
+// role to another one.
+type AdminChange struct {
+	Users    map[primitive.ObjectID][]primitive.ObjectID
+	Roles    map[primitive.ObjectID]*models.Role
+	Reassign map[primitive.ObjectID]primitive.ObjectID
+}
+
+// CheckAdminRemains refuses a change that would leave no active user with
+// AdminPermissions. Setups that already have no such user are left alone.
+func CheckAdminRemains(connection *mongo.Database, change AdminChange) error {
+	users, err, _ := repositories.QueryUsers(connection, bson.M{"status": bson.M{"$ne": constants.UserPending}})
+
+	if err != nil {
+		return err
+	}
+
+	roles, err, _ := repositories.QueryRoles(connection, bson.M{})
+
+	if err != nil {
+		return err
+	}
+
+	current := map[primitive.ObjectID]models.Role{}
+
+	for _, role := range roles {
+		current[role.ID] = role
+	}
+
+	if countAdmins(users, current, AdminChange{}) == 0 {
+		return nil
+	}
+
+	changed := map[primitive.ObjectID]models.Role{}
+
+	for id, role := range current {
+		changed[id] = role
+	}
+
+	for id, role := range change.Roles {
+		if role == nil {
+			delete(changed, id)
+		} else {
+			changed[id] = *role
+		}
+	}
+
+	if countAdmins(users, changed, change) == 0 {
+		return fmt.Errorf("At least one user must keep role management permissions")
+	}
+
+	return nil
+}
+
+func countAdmins(users []models.User, roles map[primitive.ObjectID]models.Role, change AdminChange) int {
+	count := 0
+
+	for _, user := range users {
+		roleIds, ok := change.Users[user.ID]
+
+		if ok && roleIds == nil {
+			continue
+		}
+
+		if !ok {
+			roleIds = repositories.UserRoleIDs(user)
+		}
+
+		reassigned := []primitive.ObjectID{}
+
+		for _, roleId := range roleIds {
+			if target, ok := change.Reassign[roleId]; ok {
+				roleId = target
+			}
+
+			reassigned = append(reassigned, roleId)
+		}
+
+		permissions := repositories.EffectivePermissions(resolveRoleMap(roles, reassigned))
+
+		if HasPermissions(permissions, AdminPermissions) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// resolveRoleMap is repositories.ResolveRoles over roles already in memory.
+// Missing roles are skipped, as they are about to be deleted.
+func resolveRoleMap(roles map[primitive.ObjectID]models.Role, roleIds []primitive.ObjectID) []models.Role {
+	resolved := []models.Role{}
+	visited := map[primitive.ObjectID]bool{}
+	queue := append([]primitive.ObjectID{}, roleIds...)
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if visited[id] {
+			continue
+		}
+
+		visited[id] = true
+
+		role, ok := roles[id]
+
+		if !ok {
+			continue
+		}
+
+		resolved = append(resolved, role)
+		queue = append(queue, role.ParentIDs...)
+	}
+
+	return resolved
+}
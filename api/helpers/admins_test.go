@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"auth_blog_service/models"
+)
+
+func TestCountAdmins(t *testing.T) {
+	admin := models.Role{ID: primitive.NewObjectID(), Permissions: []string{"*"}}
+	editor := models.Role{ID: primitive.NewObjectID(), Permissions: []string{"post.*"}, ParentIDs: []primitive.ObjectID{admin.ID}}
+	user := models.Role{ID: primitive.NewObjectID(), Permissions: []string{"post.create"}}
+
+	roles := map[primitive.ObjectID]models.Role{admin.ID: admin, editor.ID: editor, user.ID: user}
+
+	users := []models.User{
+		{ID: primitive.NewObjectID(), RoleID: user.ID},
+		{ID: primitive.NewObjectID(), RoleIDs: []primitive.ObjectID{user.ID, editor.ID}},
+	}
+
+	if countAdmins(users, roles, AdminChange{}) == 1 {
+		t.Log("countAdmins 01 passed")
+	} else {
+		t.Error("countAdmins 01 failed")
+	}
+
+	delete(roles, admin.ID)
+
+	if countAdmins(users, roles, AdminChange{}) == 0 {
+		t.Log("countAdmins 02 passed")
+	} else {
+		t.Error("countAdmins 02 failed")
+	}
+
+	change := AdminChange{
+		Users:    map[primitive.ObjectID][]primitive.ObjectID{users[1].ID: nil},
+		Reassign: map[primitive.ObjectID]primitive.ObjectID{user.ID: admin.ID},
+	}
+
+	roles[admin.ID] = admin
+
+	if countAdmins(users, roles, change) == 1 {
+		t.Log("countAdmins 03 passed")
+	} else {
+		t.Error("countAdmins 03 failed")
+	}
+}
@@ -3,69 +3,138 @@ package helpers
 import (
 	"fmt"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"auth_blog_service/models"
+	policy "auth_blog_service/policy"
 	repositories "auth_blog_service/repositories"
 	types "auth_blog_service/types"
 )
 
+var AccessTokenDuration = time.Minute * 45
+var RefreshTokenDuration = time.Hour * 24 * 30
+
 func CreateError(message string) func() string {
 	return func() string {
 		return message
 	}
 }
 
-func CheckPermissions(connection *mongo.Database, r *http.Request, permissions []string) (bool, types.ErrorResponse) {
-	err := types.ErrorResponse{}
+func GetBearerToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
 
-	if len(permissions) == 0 {
-		return true, err
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return ""
+	}
+
+	return authorization[7:]
+}
+
+// GetAPIKey reads a personal API key from the X-API-Key header or from an
+// "Authorization: ApiKey" header.
+func GetAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
 	}
 
 	authorization := r.Header.Get("Authorization")
 
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "ApiKey ") {
+		return ""
+	}
+
+	return authorization[7:]
+}
+
+func AuthenticateRequest(connection *mongo.Database, r *http.Request) (models.Session, bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	authorization := GetBearerToken(r)
+
 	if authorization == "" {
 		err.Error = CreateError("No authorization header found")
-		return false, err
+		return models.Session{}, false, err
 	}
 
-	authorization = authorization[7:]
-
 	session, connErr := repositories.GetSession(connection, authorization)
 
 	if connErr != nil {
 		err.Error = CreateError("Invalid session")
-		return false, err
+		return models.Session{}, false, err
 	}
 
-	if !session.Active {
+	if !session.Active || time.Now().After(session.ExpiresDate) {
 		err.Error = CreateError("Session already over")
-		return false, err
+		return models.Session{}, false, err
 	}
 
-	_, roleId, connErr := ExtractTokenMetadata(authorization)
+	repositories.TouchSession(connection, session.Token)
 
-	if connErr != nil {
-		err.Error = CreateError("Invalid token")
+	return session, true, err
+}
+
+// GetAuthenticatedUser returns the user behind the session token or API key
+// of the request.
+func GetAuthenticatedUser(connection *mongo.Database, r *http.Request) (models.User, bool, types.ErrorResponse) {
+	authorization := GetAuthorization(connection, r)
+	err := authorization.Error
+
+	if !authorization.Authenticated {
+		return models.User{}, false, err
+	}
+
+	if authorization.User.ID.IsZero() {
+		err.Error = CreateError("Authentication User doesn't exists")
+		return models.User{}, false, err
+	}
+
+	return authorization.User, true, err
+}
+
+func CheckPermissions(connection *mongo.Database, r *http.Request, permissions []string) (bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	if len(permissions) == 0 {
+		return true, err
+	}
+
+	authorization := GetAuthorization(connection, r)
+
+	if !authorization.Authenticated {
+		return false, authorization.Error
+	}
+
+	if authorization.Impersonating() && !AllowedDuringImpersonation(permissions) {
+		err.Error = CreateError("Unauthorized during Impersonation")
+		return false, err
+	}
+
+	if authorization.Scoped && !HasPermissions(authorization.Scopes, permissions) {
+		err.Error = CreateError("Unauthorized by Scope")
 		return false, err
 	}
 
-	role, connErr, _ := repositories.GetRole(connection, roleId)
+	decision := EvaluatePolicies(connection, r, permissions, nil)
 
-	if connErr != nil {
-		err.Error = CreateError("Authentication Role doesn't exists")
+	if decision.Effect == policy.Deny {
+		err.Error = CreateError("Unauthorized by Policy")
 		return false, err
 	}
 
-	if len(permissions) == 1 && Contains(role.Permissions, permissions[0]) {
+	if decision.Effect == policy.Allow {
 		return true, err
 	}
 
-	if ContainsSubSLice(permissions, role.Permissions) {
+	if authorization.RoleError != nil || len(authorization.Roles) == 0 {
+		err.Error = CreateError("Authentication Role doesn't exists")
+		return false, err
+	}
+
+	if HasPermissions(authorization.Permissions, permissions) {
 		return true, err
 	}
 
@@ -74,18 +143,33 @@ func CheckPermissions(connection *mongo.Database, r *http.Request, permissions [
 	return false, err
 }
 
-func CreateToken(userId string, roleId string) (string, error) {
+func CreateToken(userId string, roleIds []string) (string, error) {
+	atClaims := jwt.MapClaims{}
+
+	atClaims["user_id"] = userId
+
+	if len(roleIds) > 0 {
+		atClaims["role_id"] = roleIds[0]
+		atClaims["roles"] = roleIds
+	}
+
+	return CreateAccessToken(atClaims)
+}
+
+func CreateAccessToken(atClaims jwt.MapClaims) (string, error) {
 	var err error
 
-	atClaims := jwt.MapClaims{}
+	tokenId, err := GenerateRandomToken(16)
+
+	if err != nil {
+		return "", err
+	}
 
 	atClaims["authorized"] = true
-	atClaims["user_id"] = userId
-	atClaims["role_id"] = roleId
-	atClaims["exp"] = time.Now().Add(time.Minute * 45).Unix()
+	atClaims["jti"] = tokenId
+	atClaims["exp"] = time.Now().Add(AccessTokenDuration).Unix()
 
-	at := jwt.NewWithClaims(jwt.SigningMethodHS256, atClaims)
-	token, err := at.SignedString([]byte(os.Getenv("ACCESS_SECRET")))
+	token, err := SignClaims(atClaims)
 
 	if err != nil {
 		return "", err
@@ -94,13 +178,24 @@ func CreateToken(userId string, roleId string) (string, error) {
 	return token, nil
 }
 
+func SignClaims(claims jwt.MapClaims) (string, error) {
+	ring, err := GetKeyRing()
+
+	if err != nil {
+		return "", err
+	}
+
+	return ring.Sign(claims)
+}
+
 func VerifyToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(os.Getenv("ACCESS_SECRET")), nil
-	})
+	ring, err := GetKeyRing()
+
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := ring.Verify(tokenString)
 
 	if err != nil {
 		return nil, err
@@ -109,22 +204,82 @@ func VerifyToken(tokenString string) (*jwt.Token, error) {
 	return token, nil
 }
 
-func ExtractTokenMetadata(tokenString string) (string, string, error) {
+func ExtractTokenClaims(tokenString string) (jwt.MapClaims, error) {
 	token, err := VerifyToken(tokenString)
 
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(jwt.MapClaims)
 
 	if ok && token.Valid {
-		userId := fmt.Sprintf("%s", claims["user_id"])
+		return claims, nil
+	}
+
+	return nil, fmt.Errorf("Invalid token")
+}
+
+func ExtractTokenMetadata(tokenString string) (string, string, error) {
+	claims, err := ExtractTokenClaims(tokenString)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	return GetClaimString(claims, "user_id"), GetClaimString(claims, "role_id"), nil
+}
+
+func GetClaimString(claims jwt.MapClaims, key string) string {
+	value, ok := claims[key].(string)
+
+	if !ok {
+		return ""
+	}
+
+	return value
+}
+
+func GetClaimStrings(claims jwt.MapClaims, key string) []string {
+	values, ok := claims[key].([]interface{})
+
+	if !ok {
+		return nil
+	}
+
+	strs := []string{}
+
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+
+	return strs
+}
+
+// GetTokenRoleIDs returns the roles a token was issued for. Tokens from
+// before multiple roles only carry the single role_id claim.
+func GetTokenRoleIDs(claims jwt.MapClaims) []string {
+	if roles := GetClaimStrings(claims, "roles"); roles != nil {
+		return roles
+	}
+
+	if roleId := GetClaimString(claims, "role_id"); roleId != "" {
+		return []string{roleId}
+	}
+
+	return []string{}
+}
 
-		roleId := fmt.Sprintf("%s", claims["role_id"])
+// GetTokenScopes returns the scopes of an OAuth token. Tokens from the
+// first-party login have no scope claim and are limited only by their role.
+func GetTokenScopes(claims jwt.MapClaims) ([]string, bool) {
+	scope, ok := claims["scope"].(string)
 
-		return string(userId), string(roleId), nil
+	if !ok {
+		return nil, false
 	}
 
-	return "", "", err
+	return strings.Fields(scope), true
 }
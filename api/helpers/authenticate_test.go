@@ -0,0 +1,31 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestGetTokenRoleIDs(t *testing.T) {
+	roles := GetTokenRoleIDs(jwt.MapClaims{"role_id": "a", "roles": []interface{}{"a", "b"}})
+
+	if len(roles) == 2 && roles[0] == "a" && roles[1] == "b" {
+		t.Log("GetTokenRoleIDs 01 passed")
+	} else {
+		t.Error("GetTokenRoleIDs 01 failed")
+	}
+
+	roles = GetTokenRoleIDs(jwt.MapClaims{"role_id": "a"})
+
+	if len(roles) == 1 && roles[0] == "a" {
+		t.Log("GetTokenRoleIDs 02 passed")
+	} else {
+		t.Error("GetTokenRoleIDs 02 failed")
+	}
+
+	if len(GetTokenRoleIDs(jwt.MapClaims{})) == 0 {
+		t.Log("GetTokenRoleIDs 03 passed")
+	} else {
+		t.Error("GetTokenRoleIDs 03 failed")
+	}
+}
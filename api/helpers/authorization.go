@@ -0,0 +1,172 @@
+package helpers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	policy "auth_blog_service/policy"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// Authorization is what the token of a request grants. It is resolved once
+// per request, so handlers checking several permissions don't reload the
+// session and the role tree each time.
+type Authorization struct {
+	Session       models.Session
+	APIKey        *models.APIKey
+	User          models.User
+	Impersonator  models.User
+	Authenticated bool
+	Error         types.ErrorResponse
+	Scopes        []string
+	Scoped        bool
+	RoleIDs       []primitive.ObjectID
+	Roles         []models.Role
+	RoleError     error
+	Permissions   []string
+
+	policyRules []policy.Rule
+	policyErr   error
+	subject     map[string]interface{}
+}
+
+type authorizationKey struct{}
+
+type authorizationCache struct {
+	authorization *Authorization
+}
+
+// WithAuthorizationCache prepares the request to remember its Authorization.
+func WithAuthorizationCache(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), authorizationKey{}, &authorizationCache{}))
+}
+
+func GetAuthorization(connection *mongo.Database, r *http.Request) *Authorization {
+	cache, ok := r.Context().Value(authorizationKey{}).(*authorizationCache)
+
+	if ok && cache.authorization != nil {
+		return cache.authorization
+	}
+
+	authorization := resolveAuthorization(connection, r)
+
+	if ok {
+		cache.authorization = authorization
+	}
+
+	return authorization
+}
+
+func resolveAuthorization(connection *mongo.Database, r *http.Request) *Authorization {
+	if key := GetAPIKey(r); key != "" {
+		return resolveAPIKeyAuthorization(connection, key)
+	}
+
+	authorization := &Authorization{}
+
+	session, auth, err := AuthenticateRequest(connection, r)
+
+	if !auth {
+		authorization.Error = err
+		return authorization
+	}
+
+	claims, connErr := ExtractTokenClaims(session.Token)
+
+	if connErr != nil {
+		err.Error = CreateError("Invalid token")
+		authorization.Error = err
+		return authorization
+	}
+
+	authorization.Session = session
+	authorization.Authenticated = true
+	authorization.Scopes, authorization.Scoped = GetTokenScopes(claims)
+
+	if username := GetClaimString(claims, "user_id"); username != "" {
+		authorization.User, _, _ = repositories.QueryUser(connection, bson.M{"username": username})
+	}
+
+	if !session.ImpersonatorID.IsZero() {
+		authorization.Impersonator, _, _ = repositories.QueryUser(connection, bson.M{"_id": session.ImpersonatorID})
+	}
+
+	authorization.RoleIDs = tokenRoleObjectIDs(claims)
+	authorization.Roles, authorization.RoleError = repositories.ResolveRoles(connection, authorization.RoleIDs)
+	authorization.Permissions = repositories.EffectivePermissions(authorization.Roles)
+
+	return authorization
+}
+
+// resolveAPIKeyAuthorization treats the permissions of an API key like the
+// scopes of an OAuth token: the key can never do more than its owner's roles.
+func resolveAPIKeyAuthorization(connection *mongo.Database, key string) *Authorization {
+	authorization := &Authorization{}
+	err := types.ErrorResponse{}
+
+	apiKey, connErr, _ := repositories.QueryAPIKey(connection, bson.M{"hash": HashToken(key)})
+
+	if connErr != nil {
+		err.Error = CreateError("Invalid API key")
+		authorization.Error = err
+		return authorization
+	}
+
+	if !apiKey.Active || !apiKey.ExpiresDate.IsZero() && time.Now().After(apiKey.ExpiresDate) {
+		err.Error = CreateError("API key already over")
+		authorization.Error = err
+		return authorization
+	}
+
+	user, connErr, _ := repositories.QueryUser(connection, bson.M{"_id": apiKey.UserID})
+
+	if connErr != nil || user.Status == constants.UserPending {
+		err.Error = CreateError("API key User is not active")
+		authorization.Error = err
+		return authorization
+	}
+
+	repositories.TouchAPIKey(connection, apiKey.ID)
+
+	authorization.APIKey = &apiKey
+	authorization.User = user
+	authorization.Authenticated = true
+	authorization.Scopes = apiKey.Permissions
+	authorization.Scoped = true
+	authorization.RoleIDs = repositories.UserRoleIDs(user)
+	authorization.Roles, authorization.RoleError = repositories.ResolveRoles(connection, authorization.RoleIDs)
+	authorization.Permissions = repositories.EffectivePermissions(authorization.Roles)
+
+	return authorization
+}
+
+// GrantedPermissions lists the role permissions narrowed to the scopes.
+func (authorization *Authorization) GrantedPermissions() []string {
+	return effectivePermissions(authorization.Permissions, authorization.Scopes, authorization.Scoped)
+}
+
+// InPerson reports whether the user is acting on their own behalf, not
+// through an API key, an OAuth client or an impersonator.
+func (authorization *Authorization) InPerson() bool {
+	return authorization.APIKey == nil && !authorization.Scoped && !authorization.Impersonating()
+}
+
+func tokenRoleObjectIDs(claims jwt.MapClaims) []primitive.ObjectID {
+	roleIds := []primitive.ObjectID{}
+
+	for _, roleId := range GetTokenRoleIDs(claims) {
+		id, _ := primitive.ObjectIDFromHex(roleId)
+		roleIds = append(roleIds, id)
+	}
+
+	return roleIds
+}
@@ -0,0 +1,38 @@
+package helpers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"auth_blog_service/models"
+)
+
+func TestInPerson(t *testing.T) {
+	if (&Authorization{Authenticated: true}).InPerson() {
+		t.Log("InPerson 01 passed")
+	} else {
+		t.Error("InPerson 01 failed")
+	}
+
+	if !(&Authorization{Authenticated: true, Scopes: []string{"user.update.own"}, Scoped: true}).InPerson() {
+		t.Log("InPerson 02 passed")
+	} else {
+		t.Error("InPerson 02 failed")
+	}
+
+	if !(&Authorization{Authenticated: true, APIKey: &models.APIKey{}}).InPerson() {
+		t.Log("InPerson 03 passed")
+	} else {
+		t.Error("InPerson 03 failed")
+	}
+
+	impersonation := &Authorization{Authenticated: true}
+	impersonation.Session.ImpersonatorID = primitive.NewObjectID()
+
+	if !impersonation.InPerson() {
+		t.Log("InPerson 04 passed")
+	} else {
+		t.Error("InPerson 04 failed")
+	}
+}
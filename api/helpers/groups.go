@@ -0,0 +1,221 @@
+package helpers
+
+import (
+	"net/http"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+	policy "auth_blog_service/policy"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// CheckGroupPermissions grants permissions the caller holds globally, or
+// through the role they hold in the group.
+func CheckGroupPermissions(connection *mongo.Database, r *http.Request, groupId primitive.ObjectID, permissions []string) (bool, types.ErrorResponse) {
+	if auth, authErr := CheckPermissions(connection, r, permissions); auth {
+		return true, authErr
+	}
+
+	return CheckGroupRolePermissions(connection, r, groupId, permissions)
+}
+
+// CheckGroupRolePermissions only looks at the caller's role in the group.
+// OAuth tokens are still limited to their scopes.
+func CheckGroupRolePermissions(connection *mongo.Database, r *http.Request, groupId primitive.ObjectID, permissions []string) (bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	authorization := GetAuthorization(connection, r)
+
+	if !authorization.Authenticated {
+		return false, authorization.Error
+	}
+
+	if authorization.Impersonating() && !AllowedDuringImpersonation(permissions) {
+		err.Error = CreateError("Unauthorized during Impersonation")
+		return false, err
+	}
+
+	if authorization.Scoped && !HasPermissions(authorization.Scopes, permissions) {
+		err.Error = CreateError("Unauthorized by Scope")
+		return false, err
+	}
+
+	user, auth, authErr := GetAuthenticatedUser(connection, r)
+
+	if !auth {
+		return false, authErr
+	}
+
+	group, connErr, _ := repositories.QueryGroup(connection, bson.M{"_id": groupId})
+
+	if connErr != nil {
+		err.Error = CreateError("Group doesn't exist")
+		return false, err
+	}
+
+	member, ok := repositories.GetGroupMember(group, user.ID)
+
+	if !ok {
+		err.Error = CreateError("Unauthorized by Group")
+		return false, err
+	}
+
+	roles, connErr := repositories.ResolveRoles(connection, []primitive.ObjectID{member.RoleID})
+
+	if connErr != nil {
+		err.Error = CreateError("Group Role doesn't exists")
+		return false, err
+	}
+
+	if HasPermissions(repositories.EffectivePermissions(roles), permissions) {
+		return true, err
+	}
+
+	err.Error = CreateError("Unauthorized by Group Role")
+
+	return false, err
+}
+
+// CanAssignGroupRole reports whether the caller may give roleId to a member
+// of the group. The role can't grant more than the caller holds, globally or
+// through their own role in the group, so group managers can't escalate.
+func CanAssignGroupRole(connection *mongo.Database, r *http.Request, groupId primitive.ObjectID, roleId primitive.ObjectID) (bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	authorization := GetAuthorization(connection, r)
+
+	if !authorization.Authenticated {
+		return false, authorization.Error
+	}
+
+	roles, connErr := repositories.ResolveRoles(connection, []primitive.ObjectID{roleId})
+
+	if connErr != nil || len(roles) == 0 {
+		err.Error = CreateError("Group member Role doesn't exist")
+		return false, err
+	}
+
+	granted := authorization.GrantedPermissions()
+
+	if group, connErr, _ := repositories.QueryGroup(connection, bson.M{"_id": groupId}); connErr == nil {
+		if member, ok := repositories.GetGroupMember(group, authorization.User.ID); ok {
+			if memberRoles, connErr := repositories.ResolveRoles(connection, []primitive.ObjectID{member.RoleID}); connErr == nil {
+				granted = append(granted, effectivePermissions(repositories.EffectivePermissions(memberRoles), authorization.Scopes, authorization.Scoped)...)
+			}
+		}
+	}
+
+	requested := []string{}
+
+	for _, permission := range repositories.EffectivePermissions(roles) {
+		if !strings.HasPrefix(permission, DenyPrefix) {
+			requested = append(requested, permission)
+		}
+	}
+
+	if HasPermissions(granted, requested) {
+		return true, err
+	}
+
+	err.Error = CreateError("Group Role permissions exceed your own")
+
+	return false, err
+}
+
+// CanManageGroupMember reports whether the caller may remove or replace the
+// membership of userId. Their current role must be one the caller could
+// assign, so members can't be demoted or removed by weaker ones.
+func CanManageGroupMember(connection *mongo.Database, r *http.Request, groupId primitive.ObjectID, userId primitive.ObjectID) (bool, types.ErrorResponse) {
+	group, connErr, _ := repositories.QueryGroup(connection, bson.M{"_id": groupId})
+
+	if connErr != nil {
+		return false, types.ErrorResponse{Error: CreateError("Group doesn't exist")}
+	}
+
+	member, ok := repositories.GetGroupMember(group, userId)
+
+	if !ok {
+		return true, types.ErrorResponse{}
+	}
+
+	return CanAssignGroupRole(connection, r, groupId, member.RoleID)
+}
+
+// CheckPostPermissions applies CheckOwnerPermissions to a post. Posts owned
+// by a group also count as the caller's own when their group role allows it.
+// Policies are only consulted once the caller passed the same authentication,
+// impersonation and scope checks as CheckPermissions.
+func CheckPostPermissions(connection *mongo.Database, r *http.Request, post models.Post, permissions []string) (bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	authorization := GetAuthorization(connection, r)
+
+	if !authorization.Authenticated {
+		return false, authorization.Error
+	}
+
+	if authorization.Impersonating() && !AllowedDuringImpersonation(permissions) {
+		err.Error = CreateError("Unauthorized during Impersonation")
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if authorization.Scoped && !HasPermission(authorization.Scopes, permission+".own") && !HasPermission(authorization.Scopes, permission+".any") {
+			err.Error = CreateError("Unauthorized by Scope")
+			return false, err
+		}
+	}
+
+	decision := EvaluatePolicies(connection, r, permissions, PostAttributes(post))
+
+	if decision.Effect == policy.Deny {
+		return false, types.ErrorResponse{Error: CreateError("Unauthorized by Policy")}
+	}
+
+	if decision.Effect == policy.Allow {
+		return true, types.ErrorResponse{}
+	}
+
+	for _, permission := range permissions {
+		auth, authErr := CheckOwnerPermissions(connection, r, post.UserID, []string{permission})
+
+		if auth {
+			continue
+		}
+
+		if post.GroupID.IsZero() {
+			return false, authErr
+		}
+
+		if auth, _ := CheckGroupRolePermissions(connection, r, post.GroupID, []string{permission + ".any"}); auth {
+			continue
+		}
+
+		if auth, groupErr := CheckGroupRolePermissions(connection, r, post.GroupID, []string{permission + ".own"}); !auth {
+			return false, groupErr
+		}
+	}
+
+	return true, types.ErrorResponse{}
+}
+
+// PostAttributes describes a post as a policy resource.
+func PostAttributes(post models.Post) map[string]interface{} {
+	resource := map[string]interface{}{
+		"type":    "post",
+		"id":      post.ID.Hex(),
+		"ownerId": post.UserID.Hex(),
+		"status":  post.Status,
+	}
+
+	if !post.GroupID.IsZero() {
+		resource["groupId"] = post.GroupID.Hex()
+	}
+
+	return resource
+}
@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+)
+
+// ImpersonationRestricted lists permissions an impersonation token never
+// grants, whatever the roles of the impersonated user.
+var ImpersonationRestricted = []string{"role.create", "role.update", "role.delete", "user.impersonate"}
+
+// ImpersonationBlockedRoutes lists the routes, as "METHOD /path/template",
+// that mint credentials or grant roles. An impersonator could use them to
+// keep access to the account after the impersonation ends.
+var ImpersonationBlockedRoutes = []string{
+	"GET /oauth/authorize",
+	"POST /api/clients",
+	"POST /api/me/keys",
+	"PATCH /api/me",
+	"PUT /api/me/password",
+	"POST /api/mfa/enroll",
+	"POST /api/mfa/activate",
+	"DELETE /api/mfa",
+	"POST /api/groups",
+	"PUT /api/groups/{id}/members",
+	"POST /api/users/{id}/impersonate",
+}
+
+func (authorization *Authorization) Impersonating() bool {
+	return !authorization.Session.ImpersonatorID.IsZero()
+}
+
+func AllowedDuringImpersonation(permissions []string) bool {
+	for _, permission := range permissions {
+		if Contains(ImpersonationRestricted, permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CanImpersonate reports whether the impersonator holds every registered
+// permission the target has, so impersonation can't escalate privileges.
+func CanImpersonate(impersonator []string, target []string) bool {
+	for _, permission := range constants.Permissions {
+		if HasPermission(target, permission.Name) && !HasPermission(impersonator, permission.Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AuditRequest records a request made with an impersonation token. Only the
+// token claims are read, so requests rejected later still leave a trace.
+func AuditRequest(connection *mongo.Database, r *http.Request, status int) {
+	token := GetBearerToken(r)
+
+	if token == "" {
+		return
+	}
+
+	claims, err := ExtractTokenClaims(token)
+
+	if err != nil || GetClaimString(claims, "impersonator_id") == "" {
+		return
+	}
+
+	session, err := repositories.GetSession(connection, token)
+
+	if err != nil {
+		return
+	}
+
+	repositories.InsertAuditRecord(connection, models.AuditRecord{
+		ImpersonatorID: session.ImpersonatorID,
+		UserID:         session.UserID,
+		Method:         r.Method,
+		Path:           r.URL.RequestURI(),
+		Status:         status,
+		IP:             GetClientIP(r),
+	})
+}
+
+// ImpersonationBlocked reports whether the request was routed to one of the
+// ImpersonationBlockedRoutes.
+func ImpersonationBlocked(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+
+	if route == nil {
+		return false
+	}
+
+	template, err := route.GetPathTemplate()
+
+	if err != nil {
+		return false
+	}
+
+	return Contains(ImpersonationBlockedRoutes, r.Method+" "+template)
+}
+
+// RejectImpersonation answers with Forbidden when an impersonation token is
+// used on a blocked route, and reports whether the request may go on.
+func RejectImpersonation(connection *mongo.Database, w http.ResponseWriter, r *http.Request) bool {
+	if !ImpersonationBlocked(r) || !GetAuthorization(connection, r).Impersonating() {
+		return true
+	}
+
+	JSONError(fmt.Errorf("Unauthorized during Impersonation"), w, constants.Forbidden)
+
+	return false
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestAllowedDuringImpersonation(t *testing.T) {
+	if AllowedDuringImpersonation([]string{"post.create", "user.read"}) {
+		t.Log("AllowedDuringImpersonation 01 passed")
+	} else {
+		t.Error("AllowedDuringImpersonation 01 failed")
+	}
+
+	if !AllowedDuringImpersonation([]string{"role.update"}) {
+		t.Log("AllowedDuringImpersonation 02 passed")
+	} else {
+		t.Error("AllowedDuringImpersonation 02 failed")
+	}
+}
+
+func TestCanImpersonate(t *testing.T) {
+	if CanImpersonate([]string{"*"}, []string{"post.manage", "user.read"}) {
+		t.Log("CanImpersonate 01 passed")
+	} else {
+		t.Error("CanImpersonate 01 failed")
+	}
+
+	if !CanImpersonate([]string{"user.manage"}, []string{"*"}) {
+		t.Log("CanImpersonate 02 passed")
+	} else {
+		t.Error("CanImpersonate 02 failed")
+	}
+
+	if CanImpersonate([]string{"user.manage", "post.*"}, []string{"post.create", "!post.delete.any"}) {
+		t.Log("CanImpersonate 03 passed")
+	} else {
+		t.Error("CanImpersonate 03 failed")
+	}
+}
+
+func TestImpersonationBlocked(t *testing.T) {
+	router := mux.NewRouter()
+	blocked := map[string]bool{}
+
+	routes := append([]string{
+		"GET /api/me",
+		"GET /api/clients",
+		"DELETE /api/groups/{id}/members/{userId}",
+		"PUT /api/posts/{id}",
+	}, ImpersonationBlockedRoutes...)
+
+	for _, route := range routes {
+		fields := strings.Fields(route)
+
+		router.HandleFunc(fields[1], func(w http.ResponseWriter, r *http.Request) {
+			blocked[r.Method+" "+r.URL.Path] = ImpersonationBlocked(r)
+		}).Methods(fields[0])
+	}
+
+	requests := map[string]bool{
+		"GET /oauth/authorize":           true,
+		"POST /api/clients":              true,
+		"POST /api/me/keys":              true,
+		"PATCH /api/me":                  true,
+		"PUT /api/me/password":           true,
+		"POST /api/mfa/enroll":           true,
+		"POST /api/mfa/activate":         true,
+		"DELETE /api/mfa":                true,
+		"POST /api/groups":               true,
+		"PUT /api/groups/1/members":      true,
+		"POST /api/users/1/impersonate":  true,
+		"GET /api/me":                    false,
+		"GET /api/clients":               false,
+		"DELETE /api/groups/1/members/2": false,
+		"PUT /api/posts/1":               false,
+	}
+
+	for request, expected := range requests {
+		fields := strings.Fields(request)
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(fields[0], fields[1], nil))
+
+		if result, ok := blocked[request]; ok && result == expected {
+			t.Log("ImpersonationBlocked", request, "passed")
+		} else {
+			t.Error("ImpersonationBlocked", request, "failed")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// IntrospectToken describes an access or refresh token as RFC 7662 expects.
+// Anything that isn't a live token is reported as inactive with no details.
+func IntrospectToken(connection *mongo.Database, token string) types.Introspection {
+	inactive := types.Introspection{Active: false}
+
+	session, err := repositories.GetSession(connection, token)
+
+	if err == nil {
+		if !session.Active || time.Now().After(session.ExpiresDate) {
+			return inactive
+		}
+
+		claims, err := ExtractTokenClaims(token)
+
+		if err != nil {
+			return inactive
+		}
+
+		scopes, scoped := GetTokenScopes(claims)
+
+		introspection := describeGrant(connection, session.UserID, session.ClientID, scopes, scoped, tokenRoleObjectIDs(claims))
+
+		if !session.ImpersonatorID.IsZero() {
+			introspection.Actor = &types.Actor{
+				Subject:  session.ImpersonatorID.Hex(),
+				Username: GetClaimString(claims, "impersonator_id"),
+			}
+		}
+
+		introspection.TokenType = "access_token"
+		introspection.IssuedAt = session.CreatedDate.Time.Unix()
+		introspection.Expiration = session.ExpiresDate.Unix()
+
+		return introspection
+	}
+
+	refreshToken, err := repositories.GetRefreshToken(connection, HashToken(token))
+
+	if err != nil || refreshToken.Used || !refreshToken.Active || time.Now().After(refreshToken.ExpiresDate) {
+		return inactive
+	}
+
+	introspection := describeGrant(connection, refreshToken.UserID, refreshToken.ClientID, refreshToken.Scopes, refreshToken.Scopes != nil, nil)
+	introspection.TokenType = "refresh_token"
+	introspection.IssuedAt = refreshToken.CreatedDate.Time.Unix()
+	introspection.Expiration = refreshToken.ExpiresDate.Unix()
+
+	return introspection
+}
+
+// describeGrant reports what a token grants. Access tokens carry the roles
+// they were issued for in their claims; a refresh token has none, so nil
+// roleIds stands for the user's current roles, which the next access token
+// will be issued with.
+func describeGrant(connection *mongo.Database, userId primitive.ObjectID, clientId string, scopes []string, scoped bool, roleIds []primitive.ObjectID) types.Introspection {
+	introspection := types.Introspection{
+		Active:   true,
+		ClientID: clientId,
+		Scope:    strings.Join(scopes, " "),
+	}
+
+	if userId.IsZero() {
+		introspection.Subject = clientId
+	} else {
+		user, err, _ := repositories.QueryUser(connection, bson.M{"_id": userId})
+
+		if err != nil {
+			return types.Introspection{Active: false}
+		}
+
+		introspection.Subject = user.ID.Hex()
+		introspection.Username = user.UserName
+
+		if roleIds == nil {
+			roleIds = repositories.UserRoleIDs(user)
+		}
+	}
+
+	roles, err := repositories.ResolveRoles(connection, roleIds)
+
+	if err != nil || len(roles) == 0 {
+		return introspection
+	}
+
+	introspection.Role = roles[0].Name
+
+	for _, role := range roles {
+		introspection.Roles = append(introspection.Roles, role.Name)
+	}
+
+	introspection.Permissions = effectivePermissions(repositories.EffectivePermissions(roles), scopes, scoped)
+
+	return introspection
+}
+
+func effectivePermissions(permissions []string, scopes []string, scoped bool) []string {
+	if !scoped {
+		return permissions
+	}
+
+	return FilterPermissions(scopes, permissions)
+}
@@ -0,0 +1,285 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dgrijalva/jwt-go"
+
+	types "auth_blog_service/types"
+)
+
+type SigningKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.PrivateKey
+	Public  crypto.PublicKey
+}
+
+// KeyRing holds the key used to sign new tokens and every key still accepted
+// when verifying, so a retired signing key keeps working until its tokens
+// expire. A ring without a signing key falls back to HS256 with ACCESS_SECRET.
+type KeyRing struct {
+	Signing      *SigningKey
+	Verification map[string]*SigningKey
+}
+
+var keyRing *KeyRing
+var keyRingErr error
+var keyRingOnce sync.Once
+
+func GetKeyRing() (*KeyRing, error) {
+	keyRingOnce.Do(func() {
+		keyRing, keyRingErr = LoadKeyRing(
+			os.Getenv("JWT_SIGNING_KEY"),
+			strings.Split(os.Getenv("JWT_VERIFICATION_KEYS"), ","),
+		)
+	})
+
+	return keyRing, keyRingErr
+}
+
+func LoadKeyRing(signingPath string, verificationPaths []string) (*KeyRing, error) {
+	ring := &KeyRing{
+		Verification: map[string]*SigningKey{},
+	}
+
+	if signingPath != "" {
+		key, err := LoadKeyFile(signingPath)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Private == nil {
+			return nil, fmt.Errorf("Signing key %s is not a private key", signingPath)
+		}
+
+		ring.Signing = key
+		ring.Verification[key.ID] = key
+	}
+
+	for _, path := range verificationPaths {
+		path = strings.TrimSpace(path)
+
+		if path == "" {
+			continue
+		}
+
+		key, err := LoadKeyFile(path)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ring.Verification[key.ID] = key
+	}
+
+	return ring, nil
+}
+
+func LoadKeyFile(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseKeyPEM(data)
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return key, nil
+}
+
+// ParseKeyPEM accepts PKCS#8, PKCS#1 and SEC 1 private keys as well as PKIX
+// and PKCS#1 public keys.
+func ParseKeyPEM(data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return nil, fmt.Errorf("No PEM block found")
+	}
+
+	var private crypto.PrivateKey
+	var public crypto.PublicKey
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		private = key
+	} else if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		public = key
+	} else if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		public = key
+	} else {
+		return nil, fmt.Errorf("Unsupported PEM block %s", block.Type)
+	}
+
+	if private != nil {
+		signer, ok := private.(crypto.Signer)
+
+		if !ok {
+			return nil, fmt.Errorf("Unsupported private key")
+		}
+
+		public = signer.Public()
+	}
+
+	return NewSigningKey(private, public)
+}
+
+func NewSigningKey(private crypto.PrivateKey, public crypto.PublicKey) (*SigningKey, error) {
+	key := &SigningKey{
+		Private: private,
+		Public:  public,
+	}
+
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			key.Method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.Method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.Method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("Unsupported elliptic curve")
+		}
+	case ed25519.PublicKey:
+		key.Method = SigningMethodEd25519
+	default:
+		return nil, fmt.Errorf("Unsupported key type %T", public)
+	}
+
+	jwk := key.JWK()
+	key.ID = jwk.Kid
+
+	return key, nil
+}
+
+// JWK describes the public half of the key. The kid is the RFC 7638
+// thumbprint, so it is stable for a given key without extra configuration.
+func (key *SigningKey) JWK() types.JWK {
+	jwk := types.JWK{
+		Use: "sig",
+		Alg: key.Method.Alg(),
+	}
+
+	var thumbprint string
+
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		thumbprint = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, jwk.E, jwk.N)
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = base64.RawURLEncoding.EncodeToString(padBytes(pub.X.Bytes(), size))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(padBytes(pub.Y.Bytes(), size))
+		thumbprint = fmt.Sprintf(`{"crv":"%s","kty":"EC","x":"%s","y":"%s"}`, jwk.Crv, jwk.X, jwk.Y)
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		thumbprint = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, jwk.X)
+	}
+
+	sum := sha256.Sum256([]byte(thumbprint))
+	jwk.Kid = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return jwk
+}
+
+func (ring *KeyRing) JWKS() types.JWKSet {
+	set := types.JWKSet{
+		Keys: []types.JWK{},
+	}
+
+	for _, key := range ring.Verification {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
+
+func (ring *KeyRing) Sign(claims jwt.MapClaims) (string, error) {
+	if ring.Signing == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+		return token.SignedString([]byte(os.Getenv("ACCESS_SECRET")))
+	}
+
+	token := jwt.NewWithClaims(ring.Signing.Method, claims)
+	token.Header["kid"] = ring.Signing.ID
+
+	return token.SignedString(ring.Signing.Private)
+}
+
+func (ring *KeyRing) Verify(tokenString string) (*jwt.Token, error) {
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+
+		if !ok {
+			if ring.Signing != nil {
+				return nil, fmt.Errorf("missing kid header")
+			}
+
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+
+			return []byte(os.Getenv("ACCESS_SECRET")), nil
+		}
+
+		key, ok := ring.Verification[kid]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown kid: %s", kid)
+		}
+
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return key.Public, nil
+	})
+}
+
+func padBytes(bytes []byte, size int) []byte {
+	if len(bytes) >= size {
+		return bytes
+	}
+
+	padded := make([]byte, size)
+	copy(padded[size-len(bytes):], bytes)
+
+	return padded
+}
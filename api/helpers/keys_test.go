@@ -0,0 +1,125 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func writeKeyFile(t *testing.T, dir string, name string, key interface{}) string {
+	bytes, err := x509.MarshalPKCS8PrivateKey(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bytes})
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestKeyRingSignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	paths := map[string]string{
+		"RS256": writeKeyFile(t, dir, "rsa.pem", rsaKey),
+		"ES256": writeKeyFile(t, dir, "ec.pem", ecKey),
+		"EdDSA": writeKeyFile(t, dir, "ed.pem", edKey),
+	}
+
+	for alg, path := range paths {
+		ring, err := LoadKeyRing(path, []string{})
+
+		if err != nil {
+			t.Errorf("KeyRing %s 01 failed: %v", alg, err)
+			continue
+		}
+
+		if ring.Signing.Method.Alg() == alg {
+			t.Logf("KeyRing %s 01 passed", alg)
+		} else {
+			t.Errorf("KeyRing %s 01 failed", alg)
+		}
+
+		token, err := ring.Sign(jwt.MapClaims{"user_id": "user"})
+
+		if err != nil {
+			t.Errorf("KeyRing %s 02 failed: %v", alg, err)
+			continue
+		}
+
+		parsed, err := ring.Verify(token)
+
+		if err == nil && parsed.Valid && parsed.Header["kid"] == ring.Signing.ID {
+			t.Logf("KeyRing %s 02 passed", alg)
+		} else {
+			t.Errorf("KeyRing %s 02 failed: %v", alg, err)
+		}
+	}
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	oldKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, newKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	oldPath := writeKeyFile(t, dir, "old.pem", oldKey)
+	newPath := writeKeyFile(t, dir, "new.pem", newKey)
+
+	oldRing, _ := LoadKeyRing(oldPath, []string{})
+	oldToken, _ := oldRing.Sign(jwt.MapClaims{"user_id": "user"})
+
+	newRing, err := LoadKeyRing(newPath, []string{oldPath})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newRing.Verify(oldToken); err == nil {
+		t.Log("KeyRingRotation 01 passed")
+	} else {
+		t.Errorf("KeyRingRotation 01 failed: %v", err)
+	}
+
+	if len(newRing.JWKS().Keys) == 2 {
+		t.Log("KeyRingRotation 02 passed")
+	} else {
+		t.Error("KeyRingRotation 02 failed")
+	}
+
+	newToken, _ := newRing.Sign(jwt.MapClaims{"user_id": "user"})
+
+	if _, err := oldRing.Verify(newToken); err != nil {
+		t.Log("KeyRingRotation 03 passed")
+	} else {
+		t.Error("KeyRingRotation 03 failed")
+	}
+
+	hmacRing, _ := LoadKeyRing("", []string{})
+	hmacToken, _ := hmacRing.Sign(jwt.MapClaims{"user_id": "user"})
+
+	if _, err := newRing.Verify(hmacToken); err != nil {
+		t.Log("KeyRingRotation 04 passed")
+	} else {
+		t.Error("KeyRingRotation 04 failed")
+	}
+}
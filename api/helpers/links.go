@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// BuildTokenLink points at the frontend page configured in envKey, falling
+// back to defaultPath under ISSUER_URL, with the token as a query parameter.
+// Links are never built from the request, whose Host header the client
+// controls.
+func BuildTokenLink(envKey string, defaultPath string, token string) (string, error) {
+	base := os.Getenv(envKey)
+
+	if base == "" {
+		issuer, err := GetIssuer()
+
+		if err != nil {
+			return "", fmt.Errorf("Neither %s nor ISSUER_URL is configured", envKey)
+		}
+
+		base = issuer + defaultPath
+	}
+
+	link, err := url.Parse(base)
+
+	if err != nil {
+		return "", fmt.Errorf("%s is not a valid URL", envKey)
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
@@ -0,0 +1,108 @@
+package helpers
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	repositories "auth_blog_service/repositories"
+)
+
+var LoginMaxAttempts = getEnvInt("LOGIN_MAX_ATTEMPTS", 5)
+var LoginLockoutDuration = time.Minute * time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15))
+var LoginAttemptsRetention = time.Hour * 24
+
+var dummyHash string
+var dummyHashOnce sync.Once
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
+
+// LoginBackoff is how long a key must wait after its nth consecutive failure:
+// nothing after the first, then 1s, 2s, 4s... until the threshold, which
+// locks the key for LoginLockoutDuration.
+func LoginBackoff(failures int) time.Duration {
+	if failures >= LoginMaxAttempts {
+		return LoginLockoutDuration
+	}
+
+	if failures <= 1 {
+		return 0
+	}
+
+	return time.Second << uint(failures-2)
+}
+
+func UsernameAttemptKey(username string) string {
+	return "username:" + strings.ToLower(username)
+}
+
+func LoginAttemptKeys(r *http.Request, username string) []string {
+	return []string{
+		UsernameAttemptKey(username),
+		"ip:" + GetClientIP(r),
+	}
+}
+
+// CheckLoginAllowed returns how long the caller still has to wait, zero when
+// none of the keys is locked.
+func CheckLoginAllowed(connection *mongo.Database, keys []string) time.Duration {
+	var wait time.Duration
+
+	for _, key := range keys {
+		attempt, err := repositories.QueryLoginAttempt(connection, key)
+
+		if err != nil {
+			continue
+		}
+
+		if remaining := time.Until(attempt.LockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return wait
+}
+
+func RecordLoginFailure(connection *mongo.Database, keys []string) {
+	for _, key := range keys {
+		attempt, err := repositories.IncrementLoginFailures(connection, key, LoginAttemptsRetention)
+
+		if err != nil {
+			continue
+		}
+
+		if backoff := LoginBackoff(attempt.Failures); backoff > 0 {
+			repositories.LockLoginAttempt(connection, key, attempt.LastFailureDate.Add(backoff))
+		}
+	}
+}
+
+// ClearLoginFailures resets the username counter after a successful login.
+// The IP counter is kept, otherwise an attacker could reset it by logging
+// into an account of their own between guesses.
+func ClearLoginFailures(connection *mongo.Database, username string) error {
+	return repositories.ClearLoginAttempt(connection, UsernameAttemptKey(username))
+}
+
+// CheckDummyPassword spends the same bcrypt work as a real check, so a login
+// for an unknown username takes as long as one with a wrong password.
+func CheckDummyPassword(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = HashPassword("dummy-password")
+	})
+
+	CheckPasswordHash(password, dummyHash)
+}
@@ -0,0 +1,34 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginBackoff(t *testing.T) {
+	if LoginBackoff(1) == 0 {
+		t.Log("LoginBackoff 01 passed")
+	} else {
+		t.Error("LoginBackoff 01 failed")
+	}
+
+	if LoginBackoff(2) == time.Second && LoginBackoff(4) == time.Second*4 {
+		t.Log("LoginBackoff 02 passed")
+	} else {
+		t.Error("LoginBackoff 02 failed")
+	}
+
+	if LoginBackoff(LoginMaxAttempts) == LoginLockoutDuration && LoginBackoff(LoginMaxAttempts+3) == LoginLockoutDuration {
+		t.Log("LoginBackoff 03 passed")
+	} else {
+		t.Error("LoginBackoff 03 failed")
+	}
+}
+
+func TestUsernameAttemptKey(t *testing.T) {
+	if UsernameAttemptKey("Admin") == UsernameAttemptKey("admin") {
+		t.Log("UsernameAttemptKey 01 passed")
+	} else {
+		t.Error("UsernameAttemptKey 01 failed")
+	}
+}
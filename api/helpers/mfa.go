@@ -0,0 +1,132 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+var MFATokenDuration = time.Minute * 5
+var RecoveryCodesCount = 10
+
+func GetMFAIssuer() string {
+	issuer := os.Getenv("MFA_ISSUER")
+
+	if issuer == "" {
+		return "auth-blog-service"
+	}
+
+	return issuer
+}
+
+// CreateMFAToken issues the short-lived challenge returned by the password
+// step of the login. It has no session behind it, so CheckPermissions never
+// accepts it as an access token.
+func CreateMFAToken(user models.User) (string, error) {
+	tokenId, err := GenerateRandomToken(16)
+
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{}
+
+	claims["purpose"] = "mfa"
+	claims["mfa_user_id"] = user.ID.Hex()
+	claims["jti"] = tokenId
+	claims["exp"] = time.Now().Add(MFATokenDuration).Unix()
+
+	return SignClaims(claims)
+}
+
+func GetMFATokenUser(connection *mongo.Database, mfaToken string) (models.User, error) {
+	claims, err := ExtractTokenClaims(mfaToken)
+
+	if err != nil || GetClaimString(claims, "purpose") != "mfa" {
+		return models.User{}, fmt.Errorf("Invalid MFA token")
+	}
+
+	id, err := primitive.ObjectIDFromHex(GetClaimString(claims, "mfa_user_id"))
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("Invalid MFA token")
+	}
+
+	user, err, _ := repositories.QueryUser(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return models.User{}, fmt.Errorf("Invalid MFA token")
+	}
+
+	return user, nil
+}
+
+// GetMFAUser resolves the user enrolling in MFA, either from a regular
+// session or, for users whose role forces 2FA before they can log in, from
+// the login challenge token.
+func GetMFAUser(connection *mongo.Database, r *http.Request, mfaToken string) (models.User, bool, types.ErrorResponse) {
+	if mfaToken == "" {
+		return GetAuthenticatedUser(connection, r)
+	}
+
+	err := types.ErrorResponse{}
+
+	user, connErr := GetMFATokenUser(connection, mfaToken)
+
+	if connErr != nil {
+		err.Error = CreateError(connErr.Error())
+		return models.User{}, false, err
+	}
+
+	return user, true, err
+}
+
+func RequiresMFA(connection *mongo.Database, user models.User) bool {
+	return user.MFAEnabled || RoleRequiresMFA(connection, user)
+}
+
+// RoleRequiresMFA reports whether any role of the user, inherited ones
+// included, enforces two-factor authentication.
+func RoleRequiresMFA(connection *mongo.Database, user models.User) bool {
+	roles, err := repositories.ResolveRoles(connection, repositories.UserRoleIDs(user))
+
+	if err != nil {
+		return false
+	}
+
+	for _, role := range roles {
+		if role.RequireMFA {
+			return true
+		}
+	}
+
+	return false
+}
+
+func VerifyMFA(connection *mongo.Database, user models.User, code string, recoveryCode string) error {
+	if code != "" {
+		step, ok := ValidateTOTP(user.MFASecret, code, time.Now())
+
+		if !ok {
+			return fmt.Errorf("Invalid code")
+		}
+
+		return repositories.UseUserMFAStep(connection, user.ID, step)
+	}
+
+	if recoveryCode != "" {
+		return repositories.UseUserRecoveryCode(connection, user.ID, HashToken(NormalizeRecoveryCode(recoveryCode)))
+	}
+
+	return fmt.Errorf("A code or recovery code is required")
+}
@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+)
+
+var AuthorizationCodeDuration = time.Minute * 10
+
+// AuthenticateClient reads the client credentials from HTTP Basic auth or
+// from the client_id/client_secret form fields. Public clients have no secret
+// and are identified by client_id alone.
+func AuthenticateClient(connection *mongo.Database, r *http.Request) (models.Client, error) {
+	clientId, secret, ok := r.BasicAuth()
+
+	if !ok {
+		clientId = r.PostFormValue("client_id")
+		secret = r.PostFormValue("client_secret")
+	}
+
+	if clientId == "" {
+		return models.Client{}, fmt.Errorf("Client authentication is required")
+	}
+
+	client, err, _ := repositories.QueryClient(connection, bson.M{"clientId": clientId})
+
+	if err != nil {
+		return models.Client{}, fmt.Errorf("Invalid client")
+	}
+
+	if client.Public {
+		return client, nil
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return models.Client{}, fmt.Errorf("Invalid client")
+	}
+
+	return client, nil
+}
+
+func VerifyCodeChallenge(verifier string, challenge string, method string) bool {
+	if challenge == "" {
+		return verifier == ""
+	}
+
+	switch method {
+	case "S256":
+		sum := sha256.Sum256([]byte(verifier))
+		computed := base64.RawURLEncoding.EncodeToString(sum[:])
+
+		return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+	case "plain":
+		return subtle.ConstantTimeCompare([]byte(verifier), []byte(challenge)) == 1
+	}
+
+	return false
+}
+
+// AuthorizationScopes returns the scopes an authorization code may carry for
+// the caller: the requested permissions their own credential grants, plus
+// the requested OpenID scopes. Only the user in person can authorize a
+// client, not an OAuth token, an API key or an impersonation session.
+func AuthorizationScopes(authorization *Authorization, requested []string) ([]string, error) {
+	if !authorization.InPerson() {
+		return nil, fmt.Errorf("Clients can only be authorized by the user in person")
+	}
+
+	if authorization.RoleError != nil || len(authorization.Roles) == 0 {
+		return nil, fmt.Errorf("Authentication Role doesn't exists")
+	}
+
+	return append(FilterPermissions(requested, authorization.GrantedPermissions()), IntersectScopes(requested, OpenIDScopes)...), nil
+}
+
+// IntersectScopes keeps the requested scopes that are also present in
+// allowed, preserving the requested order.
+func IntersectScopes(requested []string, allowed []string) []string {
+	scopes := []string{}
+
+	for _, scope := range requested {
+		if Contains(allowed, scope) && !Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
+
+// ExcludeScopes drops the scopes present in excluded, preserving the order of
+// the rest.
+func ExcludeScopes(scopes []string, excluded []string) []string {
+	kept := []string{}
+
+	for _, scope := range scopes {
+		if !Contains(excluded, scope) {
+			kept = append(kept, scope)
+		}
+	}
+
+	return kept
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"auth_blog_service/models"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if VerifyCodeChallenge(verifier, challenge, "S256") {
+		t.Log("VerifyCodeChallenge 01 passed")
+	} else {
+		t.Error("VerifyCodeChallenge 01 failed")
+	}
+
+	if !VerifyCodeChallenge("wrong", challenge, "S256") {
+		t.Log("VerifyCodeChallenge 02 passed")
+	} else {
+		t.Error("VerifyCodeChallenge 02 failed")
+	}
+
+	if VerifyCodeChallenge("abc", "abc", "plain") {
+		t.Log("VerifyCodeChallenge 03 passed")
+	} else {
+		t.Error("VerifyCodeChallenge 03 failed")
+	}
+
+	if !VerifyCodeChallenge("abc", "abc", "S512") {
+		t.Log("VerifyCodeChallenge 04 passed")
+	} else {
+		t.Error("VerifyCodeChallenge 04 failed")
+	}
+
+	if !VerifyCodeChallenge("abc", "abc", "") {
+		t.Log("VerifyCodeChallenge 05 passed")
+	} else {
+		t.Error("VerifyCodeChallenge 05 failed")
+	}
+}
+
+func TestIntersectScopes(t *testing.T) {
+	scopes := IntersectScopes([]string{"post.create", "user.delete", "post.create"}, []string{"post.create", "post.update"})
+
+	if len(scopes) == 1 && scopes[0] == "post.create" {
+		t.Log("IntersectScopes 01 passed")
+	} else {
+		t.Error("IntersectScopes 01 failed")
+	}
+}
+
+func TestExcludeScopes(t *testing.T) {
+	scopes := ExcludeScopes([]string{"openid", "post.create", "profile"}, OpenIDScopes)
+
+	if len(scopes) == 1 && scopes[0] == "post.create" {
+		t.Log("ExcludeScopes 01 passed")
+	} else {
+		t.Error("ExcludeScopes 01 failed")
+	}
+}
+
+func TestAuthorizationScopes(t *testing.T) {
+	authorization := &Authorization{
+		Authenticated: true,
+		Roles:         []models.Role{{Name: "User"}},
+		Permissions:   []string{"post.create", "post.update.own"},
+	}
+
+	scopes, err := AuthorizationScopes(authorization, []string{"post.create", "user.delete", "openid"})
+
+	if err == nil && len(scopes) == 2 && scopes[0] == "post.create" && scopes[1] == "openid" {
+		t.Log("AuthorizationScopes 01 passed")
+	} else {
+		t.Error("AuthorizationScopes 01 failed")
+	}
+
+	authorization.Scopes = []string{"post.read"}
+	authorization.Scoped = true
+
+	if _, err := AuthorizationScopes(authorization, []string{"post.create"}); err != nil {
+		t.Log("AuthorizationScopes 02 passed")
+	} else {
+		t.Error("AuthorizationScopes 02 failed")
+	}
+
+	authorization.Scopes = nil
+	authorization.Scoped = false
+	authorization.Session.ImpersonatorID = primitive.NewObjectID()
+
+	if _, err := AuthorizationScopes(authorization, []string{"post.create"}); err != nil {
+		t.Log("AuthorizationScopes 03 passed")
+	} else {
+		t.Error("AuthorizationScopes 03 failed")
+	}
+}
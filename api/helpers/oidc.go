@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"auth_blog_service/models"
+)
+
+// OpenIDScopes are granted on top of the role permissions: they describe
+// which identity claims a client may read, not what it may do in the API.
+var OpenIDScopes = []string{"openid", "profile"}
+
+var IDTokenDuration = time.Minute * 45
+
+// GetIssuer returns ISSUER_URL. The issuer is never derived from the request,
+// since its Host and X-Forwarded-Proto headers are client input.
+func GetIssuer() (string, error) {
+	issuer := os.Getenv("ISSUER_URL")
+
+	if issuer == "" {
+		return "", fmt.Errorf("ISSUER_URL is not configured")
+	}
+
+	return strings.TrimSuffix(issuer, "/"), nil
+}
+
+// GetOpenIDIssuer returns the issuer if OpenID Connect can be offered. ID
+// tokens need an asymmetric signing key: relying parties can't verify HS256
+// tokens without the server's own secret.
+func GetOpenIDIssuer() (string, error) {
+	issuer, err := GetIssuer()
+
+	if err != nil {
+		return "", err
+	}
+
+	ring, err := GetKeyRing()
+
+	if err != nil {
+		return "", err
+	}
+
+	if ring.Signing == nil {
+		return "", fmt.Errorf("OpenID Connect requires JWT_SIGNING_KEY")
+	}
+
+	return issuer, nil
+}
+
+// UserInfoClaims builds the standard claims for the user, limited to the
+// scopes the client was granted.
+func UserInfoClaims(user models.User, scopes []string) map[string]interface{} {
+	claims := map[string]interface{}{
+		"sub": user.ID.Hex(),
+	}
+
+	if Contains(scopes, "profile") {
+		claims["name"] = user.Name
+		claims["preferred_username"] = user.UserName
+
+		if !user.BirthDate.Time.IsZero() {
+			claims["birthdate"] = user.BirthDate.Time.Format("2006-01-02")
+		}
+	}
+
+	return claims
+}
+
+func CreateIDToken(user models.User, clientId string, scopes []string, nonce string) (string, error) {
+	issuer, err := GetOpenIDIssuer()
+
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := jwt.MapClaims{}
+
+	for key, value := range UserInfoClaims(user, scopes) {
+		claims[key] = value
+	}
+
+	claims["iss"] = issuer
+	claims["aud"] = clientId
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(IDTokenDuration).Unix()
+
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	return SignClaims(claims)
+}
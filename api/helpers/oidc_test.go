@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"auth_blog_service/models"
+	types "auth_blog_service/types"
+)
+
+func TestUserInfoClaims(t *testing.T) {
+	user := models.User{
+		ID:       primitive.NewObjectID(),
+		Name:     "Test",
+		UserName: "user",
+		BirthDate: types.Datetime{
+			Time: time.Date(1996, 6, 26, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	claims := UserInfoClaims(user, []string{"openid"})
+
+	if claims["sub"] == user.ID.Hex() && claims["name"] == nil {
+		t.Log("UserInfoClaims 01 passed")
+	} else {
+		t.Error("UserInfoClaims 01 failed")
+	}
+
+	claims = UserInfoClaims(user, []string{"openid", "profile"})
+
+	if claims["preferred_username"] == "user" && claims["birthdate"] == "1996-06-26" {
+		t.Log("UserInfoClaims 02 passed")
+	} else {
+		t.Error("UserInfoClaims 02 failed")
+	}
+}
+
+func TestGetIssuer(t *testing.T) {
+	os.Setenv("ISSUER_URL", "https://auth.example.com/")
+	defer os.Unsetenv("ISSUER_URL")
+
+	if issuer, err := GetIssuer(); err == nil && issuer == "https://auth.example.com" {
+		t.Log("GetIssuer 01 passed")
+	} else {
+		t.Error("GetIssuer 01 failed")
+	}
+
+	os.Unsetenv("ISSUER_URL")
+
+	if _, err := GetIssuer(); err != nil {
+		t.Log("GetIssuer 02 passed")
+	} else {
+		t.Error("GetIssuer 02 failed")
+	}
+}
+
+func TestGetOpenIDIssuer(t *testing.T) {
+	os.Setenv("ISSUER_URL", "https://auth.example.com")
+	defer os.Unsetenv("ISSUER_URL")
+
+	if _, err := GetOpenIDIssuer(); err != nil {
+		t.Log("GetOpenIDIssuer 01 passed")
+	} else {
+		t.Error("GetOpenIDIssuer 01 failed")
+	}
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	types "auth_blog_service/types"
+)
+
+// CheckOwnerPermissions authorizes an action on a resource owned by ownerId.
+// Each permission is granted by its ".any" variant, or by its ".own" variant
+// when the caller is the owner.
+func CheckOwnerPermissions(connection *mongo.Database, r *http.Request, ownerId primitive.ObjectID, permissions []string) (bool, types.ErrorResponse) {
+	err := types.ErrorResponse{}
+
+	for _, permission := range permissions {
+		if auth, _ := CheckPermissions(connection, r, []string{permission + ".any"}); auth {
+			continue
+		}
+
+		auth, authErr := CheckPermissions(connection, r, []string{permission + ".own"})
+
+		if !auth {
+			return false, authErr
+		}
+
+		user, auth, authErr := GetAuthenticatedUser(connection, r)
+
+		if !auth {
+			return false, authErr
+		}
+
+		if user.ID != ownerId {
+			err.Error = CreateError("Unauthorized by Ownership")
+			return false, err
+		}
+	}
+
+	return true, err
+}
@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	constants "auth_blog_service/constants"
+	types "auth_blog_service/types"
+)
+
+// ParseListQuery reads the list parameters of the request. createdFrom is
+// inclusive and createdTo exclusive; both take a date or an RFC 3339 time.
+func ParseListQuery(r *http.Request) (types.ListQuery, error) {
+	values := r.URL.Query()
+
+	query := types.ListQuery{
+		Limit:    constants.DefaultPageLimit,
+		Cursor:   values.Get("cursor"),
+		Sort:     values.Get("sort"),
+		Title:    values.Get("title"),
+		UserName: values.Get("username"),
+		Name:     values.Get("name"),
+	}
+
+	if limit := values.Get("limit"); limit != "" {
+		parsed, err := strconv.ParseInt(limit, 10, 64)
+
+		if err != nil || parsed < 1 || parsed > constants.MaxPageLimit {
+			return types.ListQuery{}, fmt.Errorf("limit must be between 1 and %d", constants.MaxPageLimit)
+		}
+
+		query.Limit = parsed
+	}
+
+	if author := values.Get("author"); author != "" {
+		id, err := primitive.ObjectIDFromHex(author)
+
+		if err != nil {
+			return types.ListQuery{}, fmt.Errorf("author must be a User id")
+		}
+
+		query.Author = id
+	}
+
+	var err error
+
+	if query.CreatedFrom, err = parseListDate(values.Get("createdFrom")); err != nil {
+		return types.ListQuery{}, fmt.Errorf("createdFrom must be a date")
+	}
+
+	if query.CreatedTo, err = parseListDate(values.Get("createdTo")); err != nil {
+		return types.ListQuery{}, fmt.Errorf("createdTo must be a date")
+	}
+
+	return query, nil
+}
+
+func parseListDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.Parse("2006-01-02", value); err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"net/http"
+	"testing"
+
+	constants "auth_blog_service/constants"
+)
+
+func TestParseListQuery(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/api/posts", nil)
+
+	query, err := ParseListQuery(r)
+
+	if err == nil && query.Limit == constants.DefaultPageLimit {
+		t.Log("ParseListQuery 01 passed")
+	} else {
+		t.Error("ParseListQuery 01 failed")
+	}
+
+	r, _ = http.NewRequest("GET", "/api/posts?limit=5&sort=-createdDate&title=Go&createdFrom=2021-01-01&createdTo=2021-02-01T00:00:00Z", nil)
+
+	query, err = ParseListQuery(r)
+
+	if err == nil && query.Limit == 5 && query.Sort == "-createdDate" && query.Title == "Go" && query.CreatedFrom.Year() == 2021 && query.CreatedTo.Month() == 2 {
+		t.Log("ParseListQuery 02 passed")
+	} else {
+		t.Error("ParseListQuery 02 failed")
+	}
+
+	r, _ = http.NewRequest("GET", "/api/posts?limit=1000", nil)
+
+	if _, err = ParseListQuery(r); err != nil {
+		t.Log("ParseListQuery 03 passed")
+	} else {
+		t.Error("ParseListQuery 03 failed")
+	}
+
+	r, _ = http.NewRequest("GET", "/api/posts?author=abc", nil)
+
+	if _, err = ParseListQuery(r); err != nil {
+		t.Log("ParseListQuery 04 passed")
+	} else {
+		t.Error("ParseListQuery 04 failed")
+	}
+}
@@ -0,0 +1,122 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	constants "auth_blog_service/constants"
+)
+
+// DenyPrefix marks a permission entry that revokes what it matches, even when
+// another entry grants it.
+const DenyPrefix = "!"
+
+// MatchPermission reports whether a single grant pattern covers permission.
+// "*" matches everything and "post.*" matches every permission under "post.".
+func MatchPermission(pattern string, permission string) bool {
+	if pattern == "*" || pattern == permission {
+		return true
+	}
+
+	if strings.HasSuffix(pattern, ".*") {
+		return strings.HasPrefix(permission, strings.TrimSuffix(pattern, "*"))
+	}
+
+	return false
+}
+
+// ExpandPermissions resolves implications, returning the allowed and denied
+// patterns of granted.
+func ExpandPermissions(granted []string) ([]string, []string) {
+	allowed := []string{}
+	denied := []string{}
+
+	for _, permission := range granted {
+		if strings.HasPrefix(permission, DenyPrefix) {
+			denied = expandPermission(strings.TrimPrefix(permission, DenyPrefix), denied)
+		} else {
+			allowed = expandPermission(permission, allowed)
+		}
+	}
+
+	return allowed, denied
+}
+
+func expandPermission(permission string, expanded []string) []string {
+	if Contains(expanded, permission) {
+		return expanded
+	}
+
+	expanded = append(expanded, permission)
+
+	for implier, implied := range constants.PermissionImplications {
+		if !MatchPermission(permission, implier) {
+			continue
+		}
+
+		for _, item := range implied {
+			expanded = expandPermission(item, expanded)
+		}
+	}
+
+	return expanded
+}
+
+// HasPermission reports whether granted allows permission. Deny entries win
+// over any grant.
+func HasPermission(granted []string, permission string) bool {
+	allowed, denied := ExpandPermissions(granted)
+
+	return !matchAny(denied, permission) && matchAny(allowed, permission)
+}
+
+func HasPermissions(granted []string, permissions []string) bool {
+	allowed, denied := ExpandPermissions(granted)
+
+	for _, permission := range permissions {
+		if matchAny(denied, permission) || !matchAny(allowed, permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterPermissions keeps the requested permissions that granted allows,
+// preserving the requested order.
+func FilterPermissions(requested []string, granted []string) []string {
+	permissions := []string{}
+
+	for _, permission := range requested {
+		if HasPermission(granted, permission) && !Contains(permissions, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	return permissions
+}
+
+func matchAny(patterns []string, permission string) bool {
+	for _, pattern := range patterns {
+		if MatchPermission(pattern, permission) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckRoutePermission makes sure a permission required by a route exists in
+// the registry. Ownership-checked permissions like "post.update" need both
+// their ".own" and ".any" variants registered.
+func CheckRoutePermission(permission string) error {
+	if constants.IsRegisteredPermission(permission) {
+		return nil
+	}
+
+	if constants.IsRegisteredPermission(permission+".own") && constants.IsRegisteredPermission(permission+".any") {
+		return nil
+	}
+
+	return fmt.Errorf("Route permission %s is not registered", permission)
+}
@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	constants "auth_blog_service/constants"
+)
+
+func TestMatchPermission(t *testing.T) {
+	if MatchPermission("*", "post.create") {
+		t.Log("MatchPermission 01 passed")
+	} else {
+		t.Error("MatchPermission 01 failed")
+	}
+
+	if MatchPermission("post.*", "post.update.own") {
+		t.Log("MatchPermission 02 passed")
+	} else {
+		t.Error("MatchPermission 02 failed")
+	}
+
+	if MatchPermission("post.*", "postal.create") || MatchPermission("post.*", "post") {
+		t.Error("MatchPermission 03 failed")
+	} else {
+		t.Log("MatchPermission 03 passed")
+	}
+
+	if MatchPermission("post.update", "post.update.any") {
+		t.Error("MatchPermission 04 failed")
+	} else {
+		t.Log("MatchPermission 04 passed")
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	if HasPermission([]string{"post.manage"}, "post.delete.any") && HasPermission([]string{"post.manage"}, "post.create") {
+		t.Log("HasPermission 01 passed")
+	} else {
+		t.Error("HasPermission 01 failed")
+	}
+
+	if HasPermission([]string{"post.manage"}, "user.delete") {
+		t.Error("HasPermission 02 failed")
+	} else {
+		t.Log("HasPermission 02 passed")
+	}
+
+	if HasPermission([]string{"*", "!post.delete.any"}, "post.delete.any") {
+		t.Error("HasPermission 03 failed")
+	} else {
+		t.Log("HasPermission 03 passed")
+	}
+
+	if HasPermission([]string{"post.*", "!post.manage"}, "post.update.own") {
+		t.Error("HasPermission 04 failed")
+	} else {
+		t.Log("HasPermission 04 passed")
+	}
+
+	if HasPermission([]string{"*"}, "user.unlock") {
+		t.Log("HasPermission 05 passed")
+	} else {
+		t.Error("HasPermission 05 failed")
+	}
+}
+
+func TestHasPermissions(t *testing.T) {
+	granted := []string{"role.manage", "!role.delete"}
+
+	if HasPermissions(granted, []string{"role.read", "role.update"}) {
+		t.Log("HasPermissions 01 passed")
+	} else {
+		t.Error("HasPermissions 01 failed")
+	}
+
+	if HasPermissions(granted, []string{"role.read", "role.delete"}) {
+		t.Error("HasPermissions 02 failed")
+	} else {
+		t.Log("HasPermissions 02 passed")
+	}
+}
+
+func TestFilterPermissions(t *testing.T) {
+	permissions := FilterPermissions([]string{"post.create", "user.delete", "post.create", "post.update.any"}, []string{"post.*"})
+
+	if len(permissions) == 2 && permissions[0] == "post.create" && permissions[1] == "post.update.any" {
+		t.Log("FilterPermissions 01 passed")
+	} else {
+		t.Error("FilterPermissions 01 failed")
+	}
+}
+
+func TestCheckRoutePermission(t *testing.T) {
+	if CheckRoutePermission("role.read") == nil && CheckRoutePermission("post.update") == nil {
+		t.Log("CheckRoutePermission 01 passed")
+	} else {
+		t.Error("CheckRoutePermission 01 failed")
+	}
+
+	if CheckRoutePermission("role.reed") == nil {
+		t.Error("CheckRoutePermission 02 failed")
+	} else {
+		t.Log("CheckRoutePermission 02 passed")
+	}
+}
+
+func TestImpliedPermissionsAreRegistered(t *testing.T) {
+	for implier, implied := range constants.PermissionImplications {
+		if CheckRoutePermission(implier) != nil {
+			t.Errorf("%s is not registered", implier)
+		}
+
+		for _, permission := range implied {
+			if !strings.HasSuffix(permission, ".*") && CheckRoutePermission(permission) != nil {
+				t.Errorf("%s implied by %s is not registered", permission, implier)
+			}
+		}
+	}
+}
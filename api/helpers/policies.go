@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"auth_blog_service/models"
+	policy "auth_blog_service/policy"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+var policySource policy.Source
+var policySourceErr error
+var policySourceOnce sync.Once
+
+func GetPolicySource(connection *mongo.Database) (policy.Source, error) {
+	policySourceOnce.Do(func() {
+		policySource, policySourceErr = policy.NewSource(connection)
+	})
+
+	return policySource, policySourceErr
+}
+
+// EvaluatePolicies asks the policy engine about every permission. Any deny
+// wins; the request is allowed only when every permission is allowed, and
+// otherwise the decision is left to roles.
+func EvaluatePolicies(connection *mongo.Database, r *http.Request, permissions []string, resource map[string]interface{}) policy.Decision {
+	authorization := GetAuthorization(connection, r)
+
+	rules, err := authorization.PolicyRules(connection)
+
+	if err != nil {
+		return policy.Decision{Effect: policy.Deny}
+	}
+
+	if len(rules) == 0 {
+		return policy.Decision{Effect: policy.NotApplicable}
+	}
+
+	engine := policy.NewEngine(rules, MatchPermission)
+
+	subject := authorization.Subject()
+	environment := EnvironmentAttributes(r)
+
+	if resource == nil {
+		resource = map[string]interface{}{}
+	}
+
+	allowed := policy.Decision{Effect: policy.NotApplicable}
+	undecided := false
+
+	for _, permission := range permissions {
+		decision := engine.Evaluate(policy.Request{
+			Subject:     subject,
+			Resource:    resource,
+			Action:      permission,
+			Environment: environment,
+		})
+
+		if decision.Effect == policy.Deny {
+			return decision
+		}
+
+		if decision.Effect == policy.Allow {
+			allowed = decision
+		} else {
+			undecided = true
+		}
+	}
+
+	if undecided {
+		return policy.Decision{Effect: policy.NotApplicable}
+	}
+
+	return allowed
+}
+
+// PolicyRules loads the policy rules once per request.
+func (authorization *Authorization) PolicyRules(connection *mongo.Database) ([]policy.Rule, error) {
+	if authorization.policyRules == nil && authorization.policyErr == nil {
+		source, err := GetPolicySource(connection)
+
+		if err == nil {
+			authorization.policyRules, err = source.Rules()
+		}
+
+		authorization.policyErr = err
+	}
+
+	return authorization.policyRules, authorization.policyErr
+}
+
+// Subject describes the caller to the policy engine.
+func (authorization *Authorization) Subject() map[string]interface{} {
+	if authorization.subject == nil {
+		authorization.subject = SubjectAttributes(authorization.User, authorization.Roles, authorization.Session.ClientID)
+		authorization.subject["scopes"] = authorization.Scopes
+	}
+
+	return authorization.subject
+}
+
+func SubjectAttributes(user models.User, roles []models.Role, clientId string) map[string]interface{} {
+	roleNames := []string{}
+
+	for _, role := range roles {
+		roleNames = append(roleNames, role.Name)
+	}
+
+	subject := map[string]interface{}{
+		"roles":       roleNames,
+		"permissions": repositories.EffectivePermissions(roles),
+		"clientId":    clientId,
+	}
+
+	if !user.ID.IsZero() {
+		subject["id"] = user.ID.Hex()
+		subject["username"] = user.UserName
+		subject["mfa"] = user.MFAEnabled
+		subject["status"] = user.Status
+	}
+
+	return subject
+}
+
+// EnvironmentAttributes describes when and from where the request is made.
+// Times are in POLICY_TIMEZONE, or the server's zone when unset.
+func EnvironmentAttributes(r *http.Request) map[string]interface{} {
+	now := time.Now()
+
+	if location, err := time.LoadLocation(os.Getenv("POLICY_TIMEZONE")); err == nil {
+		now = now.In(location)
+	}
+
+	environment := map[string]interface{}{
+		"time":    now.Format(time.RFC3339),
+		"hour":    now.Hour(),
+		"weekday": now.Weekday().String(),
+	}
+
+	if r != nil {
+		environment["ip"] = GetClientIP(r)
+	}
+
+	return environment
+}
+
+// CheckUserPermission answers whether user may perform action on resource,
+// for services asking through /api/authz/check. Policies are consulted
+// first, then the user's roles; a resource whose ownerId is the user also
+// accepts the ".own" variant of the action.
+func CheckUserPermission(connection *mongo.Database, user models.User, action string, resource map[string]interface{}, environment map[string]interface{}) types.AuthzDecision {
+	roles, err := repositories.ResolveRoles(connection, repositories.UserRoleIDs(user))
+
+	if err != nil {
+		return types.AuthzDecision{Allowed: false, Effect: policy.Deny, Reason: "User Role doesn't exists"}
+	}
+
+	source, err := GetPolicySource(connection)
+
+	if err != nil {
+		return types.AuthzDecision{Allowed: false, Effect: policy.Deny, Reason: "Policies could not be loaded"}
+	}
+
+	rules, err := source.Rules()
+
+	if err != nil {
+		return types.AuthzDecision{Allowed: false, Effect: policy.Deny, Reason: "Policies could not be loaded"}
+	}
+
+	if resource == nil {
+		resource = map[string]interface{}{}
+	}
+
+	attributes := EnvironmentAttributes(nil)
+
+	for key, value := range environment {
+		attributes[key] = value
+	}
+
+	decision := policy.NewEngine(rules, MatchPermission).Evaluate(policy.Request{
+		Subject:     SubjectAttributes(user, roles, ""),
+		Resource:    resource,
+		Action:      action,
+		Environment: attributes,
+	})
+
+	switch decision.Effect {
+	case policy.Deny:
+		return types.AuthzDecision{Allowed: false, Effect: decision.Effect, Rule: decision.Rule, Reason: "Denied by policy"}
+	case policy.Allow:
+		return types.AuthzDecision{Allowed: true, Effect: decision.Effect, Rule: decision.Rule, Reason: "Allowed by policy"}
+	}
+
+	permissions := repositories.EffectivePermissions(roles)
+
+	if HasPermission(permissions, action) || HasPermission(permissions, action+".any") {
+		return types.AuthzDecision{Allowed: true, Effect: policy.Allow, Reason: "Granted by role"}
+	}
+
+	if ownerId, ok := resource["ownerId"].(string); ok && ownerId == user.ID.Hex() && HasPermission(permissions, action+".own") {
+		return types.AuthzDecision{Allowed: true, Effect: policy.Allow, Reason: "Granted by role to the owner"}
+	}
+
+	return types.AuthzDecision{Allowed: false, Effect: policy.NotApplicable, Reason: "Not granted by role"}
+}
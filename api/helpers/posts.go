@@ -0,0 +1,155 @@
+package helpers
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	search "auth_blog_service/search"
+	types "auth_blog_service/types"
+)
+
+// postReader is what decides which posts the caller may read.
+type postReader struct {
+	UserID   primitive.ObjectID
+	GroupIDs []primitive.ObjectID
+	Reviewer bool
+	Editor   bool
+}
+
+func getPostReader(connection *mongo.Database, r *http.Request) postReader {
+	reader := postReader{}
+
+	if user, auth, _ := GetAuthenticatedUser(connection, r); auth {
+		reader.UserID = user.ID
+
+		groups, _, _ := repositories.QueryGroups(connection, bson.M{"members._userId": user.ID})
+
+		for _, group := range groups {
+			reader.GroupIDs = append(reader.GroupIDs, group.ID)
+		}
+	}
+
+	reader.Reviewer, _ = CheckPermissions(connection, r, []string{"post.review"})
+	reader.Editor, _ = CheckPermissions(connection, r, []string{"post.update.any"})
+
+	return reader
+}
+
+func (reader postReader) visibility() bson.M {
+	if reader.Editor {
+		return bson.M{}
+	}
+
+	visible := []bson.M{{"status": constants.PostPublished}}
+
+	if !reader.UserID.IsZero() {
+		visible = append(visible, bson.M{"_userId": reader.UserID})
+	}
+
+	if len(reader.GroupIDs) > 0 {
+		visible = append(visible, bson.M{"_groupId": bson.M{"$in": reader.GroupIDs}})
+	}
+
+	if reader.Reviewer {
+		visible = append(visible, bson.M{"status": constants.PostInReview})
+	}
+
+	return bson.M{"$or": visible}
+}
+
+func (reader postReader) searchFilter() *search.Filter {
+	if reader.Editor {
+		return nil
+	}
+
+	filter := &search.Filter{Statuses: []string{constants.PostPublished}}
+
+	if !reader.UserID.IsZero() {
+		filter.OwnerID = reader.UserID.Hex()
+	}
+
+	for _, groupId := range reader.GroupIDs {
+		filter.GroupIDs = append(filter.GroupIDs, groupId.Hex())
+	}
+
+	if reader.Reviewer {
+		filter.Statuses = append(filter.Statuses, constants.PostInReview)
+	}
+
+	return filter
+}
+
+func (reader postReader) canRead(post models.Post) bool {
+	switch {
+	case reader.Editor, post.Status == constants.PostPublished:
+		return true
+	case !reader.UserID.IsZero() && reader.UserID == post.UserID:
+		return true
+	case !post.GroupID.IsZero() && containsObjectID(reader.GroupIDs, post.GroupID):
+		return true
+	}
+
+	return reader.Reviewer && post.Status == constants.PostInReview
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PostVisibility filters posts down to what the caller may read: published
+// posts, their own posts, posts of their groups, and posts in review for
+// reviewers. Callers who may update any post see everything.
+func PostVisibility(connection *mongo.Database, r *http.Request) bson.M {
+	return getPostReader(connection, r).visibility()
+}
+
+// PostSearchFilter is PostVisibility for the search backends, which have to
+// filter before they limit the results.
+func PostSearchFilter(connection *mongo.Database, r *http.Request) *search.Filter {
+	return getPostReader(connection, r).searchFilter()
+}
+
+// CanReadPost applies PostVisibility to a single post.
+func CanReadPost(connection *mongo.Database, r *http.Request, post models.Post) bool {
+	if post.Status == constants.PostPublished {
+		return true
+	}
+
+	return getPostReader(connection, r).canRead(post)
+}
+
+// CheckPostActionPermissions checks permissions split by ownership, like
+// "post.update", against the post's author. Others are granted globally or
+// by the caller's role in the post's group.
+func CheckPostActionPermissions(connection *mongo.Database, r *http.Request, post models.Post, permissions []string) (bool, types.ErrorResponse) {
+	for _, permission := range permissions {
+		var auth bool
+		var authErr types.ErrorResponse
+
+		if constants.IsRegisteredPermission(permission + ".own") {
+			auth, authErr = CheckPostPermissions(connection, r, post, []string{permission})
+		} else if !post.GroupID.IsZero() {
+			auth, authErr = CheckGroupPermissions(connection, r, post.GroupID, []string{permission})
+		} else {
+			auth, authErr = CheckPermissions(connection, r, []string{permission})
+		}
+
+		if !auth {
+			return false, authErr
+		}
+	}
+
+	return true, types.ErrorResponse{}
+}
@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	search "auth_blog_service/search"
+)
+
+func TestPostTransitionsAreValid(t *testing.T) {
+	statuses := []string{constants.PostDraft, constants.PostInReview, constants.PostPublished, constants.PostArchived}
+
+	for name, transition := range constants.PostTransitions {
+		if CheckRoutePermission(transition.Permission) != nil {
+			t.Errorf("%s of %s is not registered", transition.Permission, name)
+		}
+
+		if !Contains(statuses, transition.To) {
+			t.Errorf("%s moves to unknown status %s", name, transition.To)
+		}
+
+		for _, status := range transition.From {
+			if !Contains(statuses, status) {
+				t.Errorf("%s moves from unknown status %s", name, status)
+			}
+		}
+	}
+}
+
+func TestPostReader(t *testing.T) {
+	groupId := primitive.NewObjectID()
+	draft := models.Post{UserID: primitive.NewObjectID(), GroupID: groupId, Status: constants.PostDraft}
+
+	member := postReader{UserID: primitive.NewObjectID(), GroupIDs: []primitive.ObjectID{groupId}}
+	outsider := postReader{UserID: primitive.NewObjectID(), GroupIDs: []primitive.ObjectID{primitive.NewObjectID()}}
+
+	if member.canRead(draft) {
+		t.Log("PostReader 01 passed")
+	} else {
+		t.Error("PostReader 01 failed")
+	}
+
+	if !outsider.canRead(draft) && !outsider.canRead(models.Post{UserID: draft.UserID, Status: constants.PostDraft}) {
+		t.Log("PostReader 02 passed")
+	} else {
+		t.Error("PostReader 02 failed")
+	}
+
+	document := search.Document{OwnerID: draft.UserID.Hex(), GroupID: groupId.Hex(), Status: constants.PostDraft}
+
+	if member.searchFilter().Allows(document) && !outsider.searchFilter().Allows(document) {
+		t.Log("PostReader 03 passed")
+	} else {
+		t.Error("PostReader 03 failed")
+	}
+
+	if (postReader{Reviewer: true}).canRead(models.Post{Status: constants.PostInReview}) && !(postReader{}).canRead(models.Post{Status: constants.PostInReview}) {
+		t.Log("PostReader 04 passed")
+	} else {
+		t.Error("PostReader 04 failed")
+	}
+}
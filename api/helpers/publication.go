@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	serializers "auth_blog_service/serializers"
+)
+
+// PublicationHook is told about every post that gets published, whether by
+// hand or by the scheduler.
+type PublicationHook func(post serializers.Post) error
+
+var publicationHooks []PublicationHook
+var publicationHooksMutex sync.RWMutex
+
+func OnPostPublished(hook PublicationHook) {
+	publicationHooksMutex.Lock()
+	defer publicationHooksMutex.Unlock()
+
+	publicationHooks = append(publicationHooks, hook)
+}
+
+// NotifyPostPublished runs every hook in the background, so slow hooks don't
+// hold the request or the scheduler. Failures are only logged.
+func NotifyPostPublished(post serializers.Post) *sync.WaitGroup {
+	publicationHooksMutex.RLock()
+	defer publicationHooksMutex.RUnlock()
+
+	var wait sync.WaitGroup
+
+	for _, hook := range publicationHooks {
+		wait.Add(1)
+
+		go func(hook PublicationHook) {
+			defer wait.Done()
+
+			if err := hook(post); err != nil {
+				fmt.Println("Publication hook failed for post", post.ID.Hex()+":", err)
+			}
+		}(hook)
+	}
+
+	return &wait
+}
+
+// PublicationWebhook posts the published post as JSON to url.
+func PublicationWebhook(url string) PublicationHook {
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	return func(post serializers.Post) error {
+		body, err := json.Marshal(map[string]interface{}{
+			"event": "post.published",
+			"post":  post,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		response, err := client.Post(url, "application/json", bytes.NewReader(body))
+
+		if err != nil {
+			return err
+		}
+
+		defer response.Body.Close()
+
+		if response.StatusCode >= 300 {
+			return fmt.Errorf("webhook answered %d", response.StatusCode)
+		}
+
+		return nil
+	}
+}
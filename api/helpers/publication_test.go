@@ -0,0 +1,60 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serializers "auth_blog_service/serializers"
+)
+
+func TestNotifyPostPublished(t *testing.T) {
+	notified := make(chan string, 1)
+
+	OnPostPublished(func(post serializers.Post) error {
+		notified <- post.Title
+		return nil
+	})
+
+	OnPostPublished(func(post serializers.Post) error {
+		return fmt.Errorf("failing hooks don't stop the others")
+	})
+
+	NotifyPostPublished(serializers.Post{Title: "Hello"}).Wait()
+
+	if <-notified == "Hello" {
+		t.Log("NotifyPostPublished 01 passed")
+	} else {
+		t.Error("NotifyPostPublished 01 failed")
+	}
+}
+
+func TestPublicationWebhook(t *testing.T) {
+	var event map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&event)
+	}))
+	defer server.Close()
+
+	err := PublicationWebhook(server.URL)(serializers.Post{Title: "Hello"})
+
+	if err == nil && event["event"] == "post.published" {
+		t.Log("PublicationWebhook 01 passed")
+	} else {
+		t.Error("PublicationWebhook 01 failed")
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	if PublicationWebhook(failing.URL)(serializers.Post{}) != nil {
+		t.Log("PublicationWebhook 02 passed")
+	} else {
+		t.Error("PublicationWebhook 02 failed")
+	}
+}
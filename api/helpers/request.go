@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+var TrustedProxies = ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+
+// ParseTrustedProxies reads a comma-separated list of IPs and CIDR ranges,
+// skipping entries that are neither.
+func ParseTrustedProxies(value string) []*net.IPNet {
+	proxies := []*net.IPNet{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			proxies = append(proxies, network)
+		}
+	}
+
+	return proxies
+}
+
+func GetClientIP(r *http.Request) string {
+	return ClientIP(r, TrustedProxies)
+}
+
+// ClientIP returns the address of the peer, or the rightmost X-Forwarded-For
+// entry that isn't one of the trusted proxies when the peer is one. Anything
+// further left was written by the client and can't be relied on.
+func ClientIP(r *http.Request, proxies []*net.IPNet) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(ip, proxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+
+		if hop == "" {
+			continue
+		}
+
+		ip = hop
+
+		if !isTrustedProxy(hop, proxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func isTrustedProxy(address string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+
+	if ip == nil {
+		return false
+	}
+
+	for _, proxy := range proxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
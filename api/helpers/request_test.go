@@ -0,0 +1,120 @@
+package helpers
+
+import (
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestGetClientIP(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+
+	if GetClientIP(r) == "10.0.0.1" {
+		t.Log("GetClientIP 01 passed")
+	} else {
+		t.Error("GetClientIP 01 failed")
+	}
+
+	r.Header.Set("X-Forwarded-For", "192.168.0.1, 10.0.0.1")
+
+	if GetClientIP(r) == "10.0.0.1" {
+		t.Log("GetClientIP 02 passed")
+	} else {
+		t.Error("GetClientIP 02 failed")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := ParseTrustedProxies("10.0.0.0/8, 172.16.0.1, invalid")
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:5000"
+	r.Header.Set("X-Forwarded-For", "1.1.1.1, 192.168.0.1, 172.16.0.1")
+
+	if len(proxies) == 2 && ClientIP(r, proxies) == "192.168.0.1" {
+		t.Log("ClientIP 01 passed")
+	} else {
+		t.Error("ClientIP 01 failed")
+	}
+
+	r.RemoteAddr = "192.168.0.2:5000"
+
+	if ClientIP(r, proxies) == "192.168.0.2" {
+		t.Log("ClientIP 02 passed")
+	} else {
+		t.Error("ClientIP 02 failed")
+	}
+}
+
+func TestGetBearerToken(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+
+	if GetBearerToken(r) == "" {
+		t.Log("GetBearerToken 01 passed")
+	} else {
+		t.Error("GetBearerToken 01 failed")
+	}
+
+	r.Header.Set("Authorization", "Bearer abc")
+
+	if GetBearerToken(r) == "abc" {
+		t.Log("GetBearerToken 02 passed")
+	} else {
+		t.Error("GetBearerToken 02 failed")
+	}
+
+	r.Header.Set("Authorization", "ApiKey abc")
+
+	if GetBearerToken(r) == "" {
+		t.Log("GetBearerToken 03 passed")
+	} else {
+		t.Error("GetBearerToken 03 failed")
+	}
+}
+
+func TestGetAPIKey(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer abc")
+
+	if GetAPIKey(r) == "" {
+		t.Log("GetAPIKey 01 passed")
+	} else {
+		t.Error("GetAPIKey 01 failed")
+	}
+
+	r.Header.Set("Authorization", "ApiKey abc")
+
+	if GetAPIKey(r) == "abc" {
+		t.Log("GetAPIKey 02 passed")
+	} else {
+		t.Error("GetAPIKey 02 failed")
+	}
+
+	r.Header.Set("X-API-Key", "def")
+
+	if GetAPIKey(r) == "def" {
+		t.Log("GetAPIKey 03 passed")
+	} else {
+		t.Error("GetAPIKey 03 failed")
+	}
+}
+
+func TestBuildTokenLink(t *testing.T) {
+	os.Setenv("ISSUER_URL", "https://auth.example.com")
+	defer os.Unsetenv("ISSUER_URL")
+
+	if link, err := BuildTokenLink("UNSET_LINK_URL", "/reset-password", "abc"); err == nil && link == "https://auth.example.com/reset-password?token=abc" {
+		t.Log("BuildTokenLink 01 passed")
+	} else {
+		t.Error("BuildTokenLink 01 failed")
+	}
+
+	os.Unsetenv("ISSUER_URL")
+
+	if _, err := BuildTokenLink("UNSET_LINK_URL", "/reset-password", "abc"); err != nil {
+		t.Log("BuildTokenLink 02 passed")
+	} else {
+		t.Error("BuildTokenLink 02 failed")
+	}
+}
@@ -25,8 +25,28 @@ func JSONSuccess(result interface{}, w http.ResponseWriter, status int) {
 	JSONResponse(response, w, status)
 }
 
+// JSONPage writes one page of a list along with its next cursor and total.
+func JSONPage(result interface{}, page types.Page, w http.ResponseWriter, status int) {
+	var response = types.ResponseBody{
+		Status: status,
+		Result: result,
+		Next:   page.Next,
+		Total:  &page.Total,
+	}
+
+	JSONResponse(response, w, status)
+}
+
 func JSONResponse(response types.ResponseBody, w http.ResponseWriter, status int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.Status)
 	json.NewEncoder(w).Encode(response)
 }
+
+// JSONDocument writes result without the ResponseBody envelope, for
+// documents whose shape is fixed by a standard (JWKS, discovery, ...).
+func JSONDocument(result interface{}, w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
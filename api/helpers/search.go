@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"sync"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	search "auth_blog_service/search"
+	serializers "auth_blog_service/serializers"
+)
+
+var searchBackend search.Backend
+var searchBackendErr error
+var searchBackendOnce sync.Once
+
+func GetSearchBackend(connection *mongo.Database) (search.Backend, error) {
+	searchBackendOnce.Do(func() {
+		searchBackend, searchBackendErr = search.NewBackend(connection)
+	})
+
+	return searchBackend, searchBackendErr
+}
+
+// IndexPost tells the search backend about a created or updated post.
+func IndexPost(connection *mongo.Database, post serializers.Post) {
+	if backend, err := GetSearchBackend(connection); err == nil {
+		document := search.Document{ID: post.ID.Hex(), Title: post.Title, Body: post.Body, Status: post.Status, OwnerID: post.UserID.Hex()}
+
+		if !post.GroupID.IsZero() {
+			document.GroupID = post.GroupID.Hex()
+		}
+
+		backend.Index(document)
+	}
+}
+
+func RemovePost(connection *mongo.Database, id string) {
+	if backend, err := GetSearchBackend(connection); err == nil {
+		backend.Remove(id)
+	}
+}
@@ -36,3 +36,40 @@ func TestCheckPasswordHash(t *testing.T) {
 		t.Error("CheckPasswordHash 01 failed")
 	}
 }
+
+func TestGenerateRandomToken(t *testing.T) {
+	token1, err := GenerateRandomToken(32)
+	token2, _ := GenerateRandomToken(32)
+
+	if err != nil || token1 == "" {
+		t.Error("GenerateRandomToken 01 failed")
+	} else {
+		t.Log("GenerateRandomToken 01 passed")
+	}
+
+	if token1 != token2 {
+		t.Log("GenerateRandomToken 02 passed")
+	} else {
+		t.Error("GenerateRandomToken 02 failed")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("abc") == HashToken("abc") {
+		t.Log("HashToken 01 passed")
+	} else {
+		t.Error("HashToken 01 failed")
+	}
+
+	if HashToken("abc") != HashToken("abd") {
+		t.Log("HashToken 02 passed")
+	} else {
+		t.Error("HashToken 02 failed")
+	}
+
+	if HashToken("abc") != "abc" {
+		t.Log("HashToken 03 passed")
+	} else {
+		t.Error("HashToken 03 failed")
+	}
+}
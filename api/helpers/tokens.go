@@ -0,0 +1,211 @@
+package helpers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	repositories "auth_blog_service/repositories"
+	types "auth_blog_service/types"
+)
+
+// Grant describes who a token is issued to. First-party logins leave Scopes
+// nil, which means the token carries the full role; OAuth grants always set
+// them. A zero User is a client acting on its own behalf, with the RoleIDs of
+// the user who registered it.
+type Grant struct {
+	User         models.User
+	Impersonator models.User
+	RoleIDs      []primitive.ObjectID
+	ClientID     string
+	Scopes       []string
+	Family       string
+	Nonce        string
+	Refresh      bool
+}
+
+// IssueTokens starts a new session for the user and pairs it with a refresh
+// token. An empty family starts a new token family, as on login; refreshes
+// keep the family of the token they rotate.
+func IssueTokens(connection *mongo.Database, r *http.Request, user models.User, family string) (types.TokenPair, error) {
+	return IssueGrant(connection, r, Grant{
+		User:    user,
+		Family:  family,
+		Refresh: true,
+	})
+}
+
+func IssueGrant(connection *mongo.Database, r *http.Request, grant Grant) (types.TokenPair, error) {
+	var err error
+
+	claims := jwt.MapClaims{}
+
+	if !grant.User.ID.IsZero() {
+		claims["user_id"] = grant.User.UserName
+		claims["role_id"] = grant.User.RoleID.Hex()
+		claims["roles"] = roleIdStrings(repositories.UserRoleIDs(grant.User))
+	} else if len(grant.RoleIDs) > 0 {
+		claims["roles"] = roleIdStrings(grant.RoleIDs)
+	}
+
+	if !grant.Impersonator.ID.IsZero() {
+		claims["impersonator_id"] = grant.Impersonator.UserName
+	}
+
+	if grant.ClientID != "" {
+		claims["client_id"] = grant.ClientID
+	}
+
+	if grant.Scopes != nil {
+		claims["scope"] = strings.Join(grant.Scopes, " ")
+	}
+
+	accessToken, err := CreateAccessToken(claims)
+
+	if err != nil {
+		return types.TokenPair{}, err
+	}
+
+	if grant.Refresh && grant.Family == "" {
+		grant.Family, err = GenerateRandomToken(16)
+
+		if err != nil {
+			return types.TokenPair{}, err
+		}
+	}
+
+	session := models.Session{
+		UserID:         grant.User.ID,
+		Token:          accessToken,
+		IP:             GetClientIP(r),
+		UserAgent:      r.UserAgent(),
+		Family:         grant.Family,
+		ClientID:       grant.ClientID,
+		Scopes:         grant.Scopes,
+		ImpersonatorID: grant.Impersonator.ID,
+	}
+
+	_, err = repositories.StartSession(connection, session, AccessTokenDuration)
+
+	if err != nil {
+		return types.TokenPair{}, err
+	}
+
+	tokens := types.TokenPair{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(AccessTokenDuration.Seconds()),
+		Scope:       strings.Join(grant.Scopes, " "),
+	}
+
+	if Contains(grant.Scopes, "openid") && !grant.User.ID.IsZero() {
+		tokens.IDToken, err = CreateIDToken(grant.User, grant.ClientID, grant.Scopes, grant.Nonce)
+
+		if err != nil {
+			return types.TokenPair{}, err
+		}
+	}
+
+	if !grant.Refresh {
+		return tokens, nil
+	}
+
+	tokens.RefreshToken, err = GenerateRandomToken(32)
+
+	if err != nil {
+		return types.TokenPair{}, err
+	}
+
+	refreshToken := models.RefreshToken{
+		UserID:       grant.User.ID,
+		Hash:         HashToken(tokens.RefreshToken),
+		Family:       grant.Family,
+		SessionToken: accessToken,
+		ClientID:     grant.ClientID,
+		Scopes:       grant.Scopes,
+	}
+
+	_, err = repositories.StartRefreshToken(connection, refreshToken, RefreshTokenDuration)
+
+	if err != nil {
+		return types.TokenPair{}, err
+	}
+
+	return tokens, nil
+}
+
+// RefreshTokens rotates a refresh token issued to clientId (empty for
+// first-party logins). Presenting a token that was already rotated revokes
+// every token of its family, since either the holder or an attacker is
+// replaying it.
+func RefreshTokens(connection *mongo.Database, r *http.Request, token string, clientId string) (types.TokenPair, error) {
+	refreshToken, err := repositories.GetRefreshToken(connection, HashToken(token))
+
+	if err != nil || refreshToken.ClientID != clientId {
+		return types.TokenPair{}, fmt.Errorf("Invalid refresh token")
+	}
+
+	if refreshToken.Used || !refreshToken.Active {
+		repositories.RevokeTokenFamily(connection, refreshToken.Family)
+
+		return types.TokenPair{}, fmt.Errorf("Refresh token reuse detected")
+	}
+
+	if time.Now().After(refreshToken.ExpiresDate) {
+		return types.TokenPair{}, fmt.Errorf("Refresh token expired")
+	}
+
+	var user models.User
+
+	if !refreshToken.UserID.IsZero() {
+		user, err, _ = repositories.QueryUser(connection, bson.M{"_id": refreshToken.UserID})
+
+		if err != nil || !IsUserActive(user) {
+			repositories.RevokeTokenFamily(connection, refreshToken.Family)
+
+			return types.TokenPair{}, fmt.Errorf("User is not active")
+		}
+	}
+
+	err = repositories.UseRefreshToken(connection, refreshToken.Hash)
+
+	if err != nil {
+		repositories.RevokeTokenFamily(connection, refreshToken.Family)
+
+		return types.TokenPair{}, fmt.Errorf("Refresh token reuse detected")
+	}
+
+	repositories.StopSession(connection, refreshToken.SessionToken)
+
+	return IssueGrant(connection, r, Grant{
+		User:     user,
+		ClientID: refreshToken.ClientID,
+		Scopes:   refreshToken.Scopes,
+		Family:   refreshToken.Family,
+		Refresh:  true,
+	})
+}
+
+// IsUserActive reports whether the user may still get tokens. Users from
+// before registration have no status and count as active.
+func IsUserActive(user models.User) bool {
+	return user.Status == "" || user.Status == constants.UserActive
+}
+
+func roleIdStrings(roleIds []primitive.ObjectID) []string {
+	strs := []string{}
+
+	for _, roleId := range roleIds {
+		strs = append(strs, roleId.Hex())
+	}
+
+	return strs
+}
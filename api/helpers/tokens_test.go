@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"testing"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+)
+
+func TestIsUserActive(t *testing.T) {
+	if IsUserActive(models.User{}) && IsUserActive(models.User{Status: constants.UserActive}) {
+		t.Log("IsUserActive 01 passed")
+	} else {
+		t.Error("IsUserActive 01 failed")
+	}
+
+	if !IsUserActive(models.User{Status: constants.UserPending}) && !IsUserActive(models.User{Status: "disabled"}) {
+		t.Log("IsUserActive 02 passed")
+	} else {
+		t.Error("IsUserActive 02 failed")
+	}
+}
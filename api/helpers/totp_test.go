@@ -0,0 +1,67 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret "12345678901234567890" from the RFC 6238 test vectors.
+var rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(59, 0)))
+
+	if err == nil && code == "287082" {
+		t.Log("TOTPCode 01 passed")
+	} else {
+		t.Error("TOTPCode 01 failed")
+	}
+
+	code, _ = TOTPCode(rfcSecret, TOTPStep(time.Unix(1111111109, 0)))
+
+	if code == "081804" {
+		t.Log("TOTPCode 02 passed")
+	} else {
+		t.Error("TOTPCode 02 failed")
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	previous, _ := TOTPCode(rfcSecret, TOTPStep(now)-1)
+
+	if step, ok := ValidateTOTP(rfcSecret, previous, now); ok && step == TOTPStep(now)-1 {
+		t.Log("ValidateTOTP 01 passed")
+	} else {
+		t.Error("ValidateTOTP 01 failed")
+	}
+
+	old, _ := TOTPCode(rfcSecret, TOTPStep(now)-5)
+
+	if _, ok := ValidateTOTP(rfcSecret, old, now); !ok {
+		t.Log("ValidateTOTP 02 passed")
+	} else {
+		t.Error("ValidateTOTP 02 failed")
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("auth-blog-service", "admin", rfcSecret)
+
+	if strings.HasPrefix(uri, "otpauth://totp/auth-blog-service:admin?") && strings.Contains(uri, "secret="+rfcSecret) {
+		t.Log("TOTPURI 01 passed")
+	} else {
+		t.Error("TOTPURI 01 failed")
+	}
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+
+	if err == nil && len(codes) == 10 && len(codes[0]) == 9 && codes[0] != codes[1] {
+		t.Log("GenerateRecoveryCodes 01 passed")
+	} else {
+		t.Error("GenerateRecoveryCodes 01 failed")
+	}
+}
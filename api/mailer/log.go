@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+type LogMailer struct {
+	Path string
+
+	mutex sync.Mutex
+}
+
+func (m *LogMailer) Send(message Message) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var out io.Writer = os.Stdout
+
+	if m.Path != "" {
+		file, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		out = file
+	}
+
+	_, err := fmt.Fprintf(
+		out,
+		"Date: %s\nTo: %s\nSubject: %s\n\n%s\n---\n",
+		time.Now().Format(time.RFC1123Z),
+		message.To,
+		message.Subject,
+		message.Body,
+	)
+
+	return err
+}
@@ -0,0 +1,49 @@
+package mailer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogMailerSend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+
+	mail := &LogMailer{Path: path}
+
+	err := mail.Send(Message{To: "user@example.com", Subject: "Hello", Body: "First"})
+	mail.Send(Message{To: "user@example.com", Subject: "Hello", Body: "Second"})
+
+	content, _ := os.ReadFile(path)
+
+	if err == nil && strings.Contains(string(content), "To: user@example.com") {
+		t.Log("LogMailerSend 01 passed")
+	} else {
+		t.Error("LogMailerSend 01 failed")
+	}
+
+	if strings.Contains(string(content), "First") && strings.Contains(string(content), "Second") {
+		t.Log("LogMailerSend 02 passed")
+	} else {
+		t.Error("LogMailerSend 02 failed")
+	}
+}
+
+func TestNewMailer(t *testing.T) {
+	os.Setenv("MAIL_DRIVER", "smtp")
+
+	if _, ok := NewMailer().(*SMTPMailer); ok {
+		t.Log("NewMailer 01 passed")
+	} else {
+		t.Error("NewMailer 01 failed")
+	}
+
+	os.Setenv("MAIL_DRIVER", "")
+
+	if _, ok := NewMailer().(*LogMailer); ok {
+		t.Log("NewMailer 02 passed")
+	} else {
+		t.Error("NewMailer 02 failed")
+	}
+}
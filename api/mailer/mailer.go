@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"os"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(message Message) error
+}
+
+// NewMailer picks the implementation from MAIL_DRIVER: "smtp" sends real
+// mail, anything else writes messages to MAIL_LOG_PATH (or stdout) for local
+// development and tests.
+func NewMailer() Mailer {
+	if os.Getenv("MAIL_DRIVER") == "smtp" {
+		return &SMTPMailer{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("MAIL_FROM"),
+		}
+	}
+
+	return &LogMailer{
+		Path: os.Getenv("MAIL_LOG_PATH"),
+	}
+}
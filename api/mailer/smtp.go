@@ -0,0 +1,35 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	headers := []string{
+		fmt.Sprintf("From: %s", m.From),
+		fmt.Sprintf("To: %s", message.To),
+		fmt.Sprintf("Subject: %s", message.Subject),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + message.Body
+
+	return smtp.SendMail(m.Host+":"+m.Port, auth, m.From, []string{message.To}, []byte(body))
+}
@@ -10,11 +10,16 @@ import (
 
 	"github.com/gorilla/mux"
 
+	constants "auth_blog_service/constants"
 	controllers "auth_blog_service/controllers"
 	db "auth_blog_service/db"
+	helpers "auth_blog_service/helpers"
+	mailer "auth_blog_service/mailer"
+	scheduler "auth_blog_service/scheduler"
 )
 
 var connection = db.ConnectDB()
+var mail = mailer.NewMailer()
 
 func logHandler(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -30,41 +35,160 @@ func logHandler(fn http.HandlerFunc) http.HandlerFunc {
 
 		fmt.Println(requestInfo[0], requestInfo[1])
 
-		fn(w, r)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		r = helpers.WithAuthorizationCache(r)
+
+		if helpers.RejectImpersonation(connection, recorder, r) {
+			fn(recorder, r)
+		}
+
+		helpers.AuditRequest(connection, r, recorder.status)
+	}
+}
+
+// statusRecorder remembers the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	recorder.status = status
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+// permission fails startup when a route requires a permission missing from
+// the registry, so typos can't silently lock a route.
+func permission(name string) string {
+	if err := helpers.CheckRoutePermission(name); err != nil {
+		log.Fatal(err)
 	}
+
+	return name
 }
 
 func main() {
 	r := mux.NewRouter()
 
+	if _, err := helpers.GetKeyRing(); err != nil {
+		log.Fatal(err)
+	}
+
+	if _, err := helpers.GetPolicySource(connection); err != nil {
+		log.Fatal(err)
+	}
+
 	db.Seed(connection)
 	db.Migrate(connection)
 
-	r.HandleFunc("/health", logHandler(HealthResponse)).Methods("GET")
+	if _, err := helpers.GetSearchBackend(connection); err != nil {
+		log.Fatal(err)
+	}
+
+	if url := os.Getenv("PUBLICATION_WEBHOOK_URL"); url != "" {
+		helpers.OnPostPublished(helpers.PublicationWebhook(url))
+	}
+
+	publisher, err := scheduler.NewScheduler(connection)
 
-	r.HandleFunc("/api/roles", logHandler(controllers.GetRoles(connection, "role.read"))).Methods("GET")
-	r.HandleFunc("/api/roles", logHandler(controllers.CreateRole(connection, "role.create"))).Methods("POST")
-	r.HandleFunc("/api/roles/{id}", logHandler(controllers.GetRoleById(connection, "role.read"))).Methods("GET")
-	r.HandleFunc("/api/roles/{id}", logHandler(controllers.UpdateRoleById(connection, "role.update"))).Methods("PUT")
-	r.HandleFunc("/api/roles/{id}", logHandler(controllers.DeleteRoleById(connection, "role.delete"))).Methods("DELETE")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	go publisher.Run(nil)
 
-	r.HandleFunc("/api/users", logHandler(controllers.GetUsers(connection, "user.read"))).Methods("GET")
-	r.HandleFunc("/api/users", logHandler(controllers.CreateUser(connection, "user.create"))).Methods("POST")
-	r.HandleFunc("/api/users/{id}", logHandler(controllers.GetUserById(connection, "user.read"))).Methods("GET")
+	r.HandleFunc("/health", logHandler(HealthResponse)).Methods("GET")
+	r.HandleFunc("/.well-known/jwks.json", logHandler(controllers.GetJWKS(connection))).Methods("GET")
+	r.HandleFunc("/.well-known/openid-configuration", logHandler(controllers.GetOpenIDConfiguration(connection))).Methods("GET")
+
+	r.HandleFunc("/api/roles", logHandler(controllers.GetRoles(connection, permission("role.read")))).Methods("GET")
+	r.HandleFunc("/api/roles", logHandler(controllers.CreateRole(connection, permission("role.create")))).Methods("POST")
+	r.HandleFunc("/api/roles/{id}", logHandler(controllers.GetRoleById(connection, permission("role.read")))).Methods("GET")
+	r.HandleFunc("/api/roles/{id}", logHandler(controllers.UpdateRoleById(connection, permission("role.update")))).Methods("PUT")
+	r.HandleFunc("/api/roles/{id}", logHandler(controllers.DeleteRoleById(connection, permission("role.delete")))).Methods("DELETE")
+
+	r.HandleFunc("/api/permissions", logHandler(controllers.GetPermissions(connection, permission("role.read")))).Methods("GET")
+
+	r.HandleFunc("/api/users", logHandler(controllers.GetUsers(connection, permission("user.read")))).Methods("GET")
+	r.HandleFunc("/api/users", logHandler(controllers.CreateUser(connection, permission("user.create")))).Methods("POST")
+	r.HandleFunc("/api/users/{id}", logHandler(controllers.GetUserById(connection, permission("user.read")))).Methods("GET")
+	r.HandleFunc("/api/users/{id}/impersonate", logHandler(controllers.ImpersonateUserById(connection, permission("user.impersonate")))).Methods("POST")
+	r.HandleFunc("/api/users/{id}/impersonations", logHandler(controllers.GetUserImpersonationsById(connection, permission("audit.read")))).Methods("GET")
 	r.HandleFunc("/api/users/{id}/role", logHandler(controllers.GetUserRoleById(connection))).Methods("GET")
+	r.HandleFunc("/api/users/{id}/roles", logHandler(controllers.GetUserRolesById(connection))).Methods("GET")
 	r.HandleFunc("/api/users/{id}/posts", logHandler(controllers.GetUserPostsById(connection))).Methods("GET")
-	r.HandleFunc("/api/users/{id}", logHandler(controllers.UpdateUserById(connection, "user.update"))).Methods("PUT")
-	r.HandleFunc("/api/users/{id}", logHandler(controllers.DeleteUserById(connection, "user.delete"))).Methods("DELETE")
+	r.HandleFunc("/api/users/{id}/unlock", logHandler(controllers.UnlockUserById(connection, permission("user.unlock")))).Methods("POST")
+	r.HandleFunc("/api/users/{id}/sessions", logHandler(controllers.DeleteUserSessionsById(connection, permission("session.delete")))).Methods("DELETE")
+	r.HandleFunc("/api/users/{id}", logHandler(controllers.UpdateUserById(connection, permission("user.update")))).Methods("PUT")
+	r.HandleFunc("/api/users/{id}", logHandler(controllers.DeleteUserById(connection, permission("user.delete")))).Methods("DELETE")
 
 	r.HandleFunc("/api/posts", logHandler(controllers.GetPosts(connection))).Methods("GET")
-	r.HandleFunc("/api/posts", logHandler(controllers.CreatePost(connection, "post.create"))).Methods("POST")
+	r.HandleFunc("/api/posts/search", logHandler(controllers.SearchPosts(connection))).Methods("GET")
+	r.HandleFunc("/api/posts", logHandler(controllers.CreatePost(connection, permission("post.create")))).Methods("POST")
 	r.HandleFunc("/api/posts/{id}", logHandler(controllers.GetPostById(connection))).Methods("GET")
-	r.HandleFunc("/api/posts/{id}", logHandler(controllers.UpdatePostById(connection, "post.update"))).Methods("PUT")
-	r.HandleFunc("/api/posts/{id}", logHandler(controllers.DeletePostById(connection, "post.delete"))).Methods("DELETE")
+	r.HandleFunc("/api/posts/{id}", logHandler(controllers.UpdatePostById(connection, permission("post.update")))).Methods("PUT")
+	r.HandleFunc("/api/posts/{id}", logHandler(controllers.DeletePostById(connection, permission("post.delete")))).Methods("DELETE")
+
+	for name, transition := range constants.PostTransitions {
+		r.HandleFunc("/api/posts/{id}/"+name, logHandler(controllers.TransitionPostById(connection, name, permission(transition.Permission)))).Methods("POST")
+	}
+
+	r.HandleFunc("/api/posts/{id}/schedule", logHandler(controllers.SchedulePostById(connection, permission("post.publish")))).Methods("PUT")
+	r.HandleFunc("/api/posts/{id}/schedule", logHandler(controllers.UnschedulePostById(connection, permission("post.publish")))).Methods("DELETE")
+
+	r.HandleFunc("/api/posts/{id}/revisions", logHandler(controllers.GetPostRevisionsById(connection, permission("post.update")))).Methods("GET")
+	r.HandleFunc("/api/posts/{id}/revisions/diff", logHandler(controllers.DiffPostRevisionsById(connection, permission("post.update")))).Methods("GET")
+	r.HandleFunc("/api/posts/{id}/revisions/{number}", logHandler(controllers.GetPostRevisionById(connection, permission("post.update")))).Methods("GET")
+	r.HandleFunc("/api/posts/{id}/revisions/{number}/restore", logHandler(controllers.RestorePostRevisionById(connection, permission("post.update")))).Methods("POST")
+
+	r.HandleFunc("/api/groups", logHandler(controllers.GetGroups(connection, permission("group.read")))).Methods("GET")
+	r.HandleFunc("/api/groups", logHandler(controllers.CreateGroup(connection, permission("group.create")))).Methods("POST")
+	r.HandleFunc("/api/groups/{id}", logHandler(controllers.GetGroupById(connection, permission("group.read")))).Methods("GET")
+	r.HandleFunc("/api/groups/{id}", logHandler(controllers.UpdateGroupById(connection, permission("group.update")))).Methods("PUT")
+	r.HandleFunc("/api/groups/{id}", logHandler(controllers.DeleteGroupById(connection, permission("group.delete")))).Methods("DELETE")
+	r.HandleFunc("/api/groups/{id}/members", logHandler(controllers.SetGroupMemberById(connection, permission("group.update")))).Methods("PUT")
+	r.HandleFunc("/api/groups/{id}/members/{userId}", logHandler(controllers.RemoveGroupMemberById(connection, permission("group.update")))).Methods("DELETE")
+	r.HandleFunc("/api/groups/{id}/posts", logHandler(controllers.GetGroupPostsById(connection))).Methods("GET")
+
+	r.HandleFunc("/api/clients", logHandler(controllers.GetClients(connection, permission("client.read")))).Methods("GET")
+	r.HandleFunc("/api/clients", logHandler(controllers.CreateClient(connection, permission("client.create")))).Methods("POST")
+	r.HandleFunc("/api/clients/{id}", logHandler(controllers.GetClientById(connection, permission("client.read")))).Methods("GET")
+	r.HandleFunc("/api/clients/{id}", logHandler(controllers.DeleteClientById(connection, permission("client.delete")))).Methods("DELETE")
+
+	r.HandleFunc("/api/me", logHandler(controllers.GetMe(connection))).Methods("GET")
+	r.HandleFunc("/api/me", logHandler(controllers.UpdateMe(connection, mail))).Methods("PATCH")
+	r.HandleFunc("/api/me/password", logHandler(controllers.UpdateMyPassword(connection))).Methods("PUT")
+	r.HandleFunc("/api/me/posts", logHandler(controllers.GetMyPosts(connection))).Methods("GET")
+	r.HandleFunc("/api/me/permissions", logHandler(controllers.GetMyPermissions(connection))).Methods("GET")
+	r.HandleFunc("/api/me/keys", logHandler(controllers.GetMyAPIKeys(connection))).Methods("GET")
+	r.HandleFunc("/api/me/keys", logHandler(controllers.CreateMyAPIKey(connection))).Methods("POST")
+	r.HandleFunc("/api/me/keys/{id}", logHandler(controllers.RevokeMyAPIKey(connection))).Methods("DELETE")
+
+	r.HandleFunc("/api/sessions", logHandler(controllers.GetMySessions(connection))).Methods("GET")
+	r.HandleFunc("/api/sessions", logHandler(controllers.DeleteMySessions(connection))).Methods("DELETE")
+	r.HandleFunc("/api/sessions/{id}", logHandler(controllers.DeleteMySessionById(connection))).Methods("DELETE")
 
 	r.HandleFunc("/api/login", logHandler(controllers.Login(connection))).Methods("POST")
+	r.HandleFunc("/api/register", logHandler(controllers.Register(connection, mail))).Methods("POST")
+	r.HandleFunc("/api/register/verify", logHandler(controllers.VerifyEmail(connection))).Methods("GET", "POST")
+	r.HandleFunc("/api/register/resend", logHandler(controllers.ResendEmailVerification(connection, mail))).Methods("POST")
+	r.HandleFunc("/api/password/forgot", logHandler(controllers.ForgotPassword(connection, mail))).Methods("POST")
+	r.HandleFunc("/api/password/reset", logHandler(controllers.ResetPassword(connection))).Methods("POST")
+	r.HandleFunc("/api/login/mfa", logHandler(controllers.LoginMFA(connection))).Methods("POST")
+	r.HandleFunc("/api/mfa/enroll", logHandler(controllers.EnrollMFA(connection))).Methods("POST")
+	r.HandleFunc("/api/mfa/activate", logHandler(controllers.ActivateMFA(connection))).Methods("POST")
+	r.HandleFunc("/api/mfa", logHandler(controllers.DisableMFA(connection))).Methods("DELETE")
+	r.HandleFunc("/api/token/refresh", logHandler(controllers.RefreshToken(connection))).Methods("POST")
+	r.HandleFunc("/api/authz/check", logHandler(controllers.CheckAuthorization(connection, permission("authz.check")))).Methods("POST")
+	r.HandleFunc("/api/token/introspect", logHandler(controllers.IntrospectToken(connection))).Methods("POST")
+	r.HandleFunc("/api/token/revoke", logHandler(controllers.RevokeToken(connection))).Methods("POST")
 	r.HandleFunc("/api/logout", logHandler(controllers.Logout(connection))).Methods("POST")
 
+	r.HandleFunc("/oauth/authorize", logHandler(controllers.Authorize(connection))).Methods("GET")
+	r.HandleFunc("/oauth/token", logHandler(controllers.Token(connection))).Methods("POST")
+	r.HandleFunc("/userinfo", logHandler(controllers.UserInfo(connection))).Methods("GET", "POST")
+
 	var port = os.Getenv("PORT")
 
 	fmt.Println("Server ready at http://localhost:" + port + "/")
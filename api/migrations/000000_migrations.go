@@ -33,6 +33,78 @@ var list = []types.Migration{
 		Name:           "add_new_permissions_to_admin",
 		Implementation: AddNewPermissionsToAdmin,
 	},
+	{
+		Name:           "add_expiry_to_sessions",
+		Implementation: AddExpiryToSessions,
+	},
+	{
+		Name:           "add_session_permissions_to_admin",
+		Implementation: AddSessionPermissionsToAdmin,
+	},
+	{
+		Name:           "add_client_permissions_to_admin",
+		Implementation: AddClientPermissionsToAdmin,
+	},
+	{
+		Name:           "add_expiry_to_authorization_codes",
+		Implementation: AddExpiryToAuthorizationCodes,
+	},
+	{
+		Name:           "add_indexes_to_login_attempts",
+		Implementation: AddIndexesToLoginAttempts,
+	},
+	{
+		Name:           "add_unlock_permission_to_admin",
+		Implementation: AddUnlockPermissionToAdmin,
+	},
+	{
+		Name:           "add_expiry_to_password_resets",
+		Implementation: AddExpiryToPasswordResets,
+	},
+	{
+		Name:           "add_email_indexes",
+		Implementation: AddEmailIndexes,
+	},
+	{
+		Name:           "split_post_permissions_by_ownership",
+		Implementation: SplitPostPermissionsByOwnership,
+	},
+	{
+		Name:           "grant_wildcard_to_admin",
+		Implementation: GrantWildcardToAdmin,
+	},
+	{
+		Name:           "add_role_lists_to_users",
+		Implementation: AddRoleListsToUsers,
+	},
+	{
+		Name:           "add_indexes_to_api_keys",
+		Implementation: AddIndexesToAPIKeys,
+	},
+	{
+		Name:           "add_indexes_to_audit",
+		Implementation: AddIndexesToAudit,
+	},
+	{
+		Name:           "add_list_indexes_to_posts",
+		Implementation: AddListIndexesToPosts,
+	},
+	{
+		Name:           "add_text_index_to_posts",
+		Implementation: AddTextIndexToPosts,
+	},
+	{
+		Name:           "add_status_to_posts",
+		Implementation: AddStatusToPosts,
+	},
+	{
+		Name:           "add_publish_at_index_to_posts",
+		Implementation: AddPublishAtIndexToPosts,
+	},
+	{
+		Name:           "add_indexes_to_post_revisions",
+		Implementation: AddIndexesToPostRevisions,
+	},
 }
 
 func GetList() []types.Migration {
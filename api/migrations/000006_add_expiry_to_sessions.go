@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+
+	helpers "auth_blog_service/helpers"
+)
+
+func AddExpiryToSessions(connection *mongo.Database) {
+	update := bson.M{
+		"$set": bson.M{
+			"expiresDate": time.Now().Add(helpers.AccessTokenDuration),
+		},
+	}
+
+	_, err := connection.Collection("sessions").UpdateMany(context.TODO(), bson.M{"expiresDate": bson.M{"$exists": false}}, update)
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, collection := range []string{"sessions", "refresh_tokens"} {
+		index := mongo.IndexModel{
+			Keys:    bson.M{"expiresDate": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		}
+
+		_, err = connection.Collection(collection).Indexes().CreateOne(context.TODO(), index)
+
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddSessionPermissionsToAdmin(connection *mongo.Database) {
+	update := bson.M{
+		"$addToSet": bson.M{
+			"permissions": "session.delete",
+		},
+	}
+
+	_, err := connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"name": "Admin"}, update)
+
+	if err != nil {
+		panic(err)
+	}
+}
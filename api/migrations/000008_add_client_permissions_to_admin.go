@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddClientPermissionsToAdmin(connection *mongo.Database) {
+	update := bson.M{
+		"$addToSet": bson.M{
+			"permissions": bson.M{
+				"$each": []string{
+					"client.read",
+					"client.create",
+					"client.delete",
+				},
+			},
+		},
+	}
+
+	_, err := connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"name": "Admin"}, update)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddIndexesToLoginAttempts(connection *mongo.Database) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"key": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.M{"expiresDate": 1},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := connection.Collection("login_attempts").Indexes().CreateMany(context.TODO(), indexes)
+
+	if err != nil {
+		panic(err)
+	}
+}
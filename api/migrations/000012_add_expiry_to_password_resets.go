@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddExpiryToPasswordResets(connection *mongo.Database) {
+	index := mongo.IndexModel{
+		Keys:    bson.M{"expiresDate": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	_, err := connection.Collection("password_resets").Indexes().CreateOne(context.TODO(), index)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddEmailIndexes(connection *mongo.Database) {
+	emailIndex := mongo.IndexModel{
+		Keys: bson.M{"email": 1},
+		Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+			"email": bson.M{"$gt": ""},
+		}),
+	}
+
+	_, err := connection.Collection("users").Indexes().CreateOne(context.TODO(), emailIndex)
+
+	if err != nil {
+		panic(err)
+	}
+
+	expiryIndex := mongo.IndexModel{
+		Keys:    bson.M{"expiresDate": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+
+	_, err = connection.Collection("email_verifications").Indexes().CreateOne(context.TODO(), expiryIndex)
+
+	if err != nil {
+		panic(err)
+	}
+}
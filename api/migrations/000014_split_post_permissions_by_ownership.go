@@ -0,0 +1,57 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// SplitPostPermissionsByOwnership turns the old post.update and post.delete
+// permissions into their ".any" variants and lets the User role manage its
+// own posts.
+func SplitPostPermissionsByOwnership(connection *mongo.Database) {
+	for _, permission := range []string{"post.update", "post.delete"} {
+		_, err := connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"permissions": permission}, bson.M{
+			"$addToSet": bson.M{
+				"permissions": permission + ".any",
+			},
+		})
+
+		if err != nil {
+			panic(err)
+		}
+
+		_, err = connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"permissions": permission}, bson.M{
+			"$pull": bson.M{
+				"permissions": permission,
+			},
+		})
+
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	_, err := connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"name": "Admin"}, bson.M{
+		"$addToSet": bson.M{
+			"permissions": "post.create.any",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"name": "User"}, bson.M{
+		"$addToSet": bson.M{
+			"permissions": bson.M{
+				"$each": []string{"post.create", "post.update.own", "post.delete.own"},
+			},
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// GrantWildcardToAdmin gives Admin every permission, including ones added
+// later, so new permissions no longer need a migration for it.
+func GrantWildcardToAdmin(connection *mongo.Database) {
+	update := bson.M{
+		"$addToSet": bson.M{
+			"permissions": "*",
+		},
+	}
+
+	_, err := connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"name": "Admin"}, update)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	repositories "auth_blog_service/repositories"
+)
+
+func AddRoleListsToUsers(connection *mongo.Database) {
+	users, err, _ := repositories.QueryUsers(connection, bson.M{"_roleIds": bson.M{"$exists": false}})
+
+	if err != nil {
+		panic(err)
+	}
+
+	for _, user := range users {
+		update := bson.M{
+			"$set": bson.M{
+				"_roleIds": []primitive.ObjectID{user.RoleID},
+			},
+		}
+
+		_, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": user.ID}, update)
+
+		if err != nil {
+			panic(err)
+		}
+	}
+}
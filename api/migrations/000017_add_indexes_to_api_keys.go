@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddIndexesToAPIKeys(connection *mongo.Database) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.M{"hash": 1},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.M{"_userId": 1},
+		},
+	}
+
+	_, err := connection.Collection("api_keys").Indexes().CreateMany(context.TODO(), indexes)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func AddIndexesToAudit(connection *mongo.Database) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.M{"_userId": 1},
+		},
+		{
+			Keys: bson.M{"_impersonatorId": 1},
+		},
+	}
+
+	_, err := connection.Collection("audit").Indexes().CreateMany(context.TODO(), indexes)
+
+	if err != nil {
+		panic(err)
+	}
+}
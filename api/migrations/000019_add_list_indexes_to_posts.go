@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AddListIndexesToPosts backs the sorts and filters of the post lists. Each
+// sort field is paired with _id, the tie breaker of the cursors.
+func AddListIndexesToPosts(connection *mongo.Database) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: primitive.D{{Key: "_userId", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: primitive.D{{Key: "createdDate.time", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		{
+			Keys: primitive.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}},
+		},
+	}
+
+	_, err := connection.Collection("posts").Indexes().CreateMany(context.TODO(), indexes)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddTextIndexToPosts backs post search. Title words weigh twice as much as
+// body words.
+func AddTextIndexToPosts(connection *mongo.Database) {
+	index := mongo.IndexModel{
+		Keys:    bson.M{"title": "text", "body": "text"},
+		Options: options.Index().SetName("posts_text").SetWeights(bson.M{"title": 2, "body": 1}),
+	}
+
+	_, err := connection.Collection("posts").Indexes().CreateOne(context.TODO(), index)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddStatusToPosts publishes the posts written before the post lifecycle,
+// since they were all public.
+func AddStatusToPosts(connection *mongo.Database) {
+	_, err := connection.Collection("posts").UpdateMany(context.TODO(), bson.M{"status": bson.M{"$exists": false}}, bson.M{
+		"$set": bson.M{
+			"status": "published",
+		},
+	})
+
+	if err != nil {
+		panic(err)
+	}
+
+	index := mongo.IndexModel{
+		Keys: primitive.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}},
+	}
+
+	_, err = connection.Collection("posts").Indexes().CreateOne(context.TODO(), index)
+
+	if err != nil {
+		panic(err)
+	}
+}
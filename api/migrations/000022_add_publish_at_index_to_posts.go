@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AddPublishAtIndexToPosts lets the scheduler find due posts without
+// scanning the ones never scheduled.
+func AddPublishAtIndexToPosts(connection *mongo.Database) {
+	index := mongo.IndexModel{
+		Keys:    bson.M{"publishAt": 1},
+		Options: options.Index().SetSparse(true),
+	}
+
+	_, err := connection.Collection("posts").Indexes().CreateOne(context.TODO(), index)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func AddIndexesToPostRevisions(connection *mongo.Database) {
+	index := mongo.IndexModel{
+		Keys:    primitive.D{{Key: "_postId", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+
+	_, err := connection.Collection("post_revisions").Indexes().CreateOne(context.TODO(), index)
+
+	if err != nil {
+		panic(err)
+	}
+}
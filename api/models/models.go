@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	types "auth_blog_service/types"
@@ -10,23 +12,72 @@ type Role struct {
 	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
 	Name        string             `json:"name" bson:"name"`
 	Permissions []string           `json:"permissions" bson:"permissions"`
+	RequireMFA  bool               `json:"requireMfa" bson:"requireMfa"`
+	// ParentIDs are roles whose permissions this role inherits.
+	ParentIDs []primitive.ObjectID `json:"_parentIds" bson:"_parentIds"`
 }
 
 type User struct {
-	ID        primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	RoleID    primitive.ObjectID `json:"_roleId" bson:"_roleId"`
-	Name      string             `json:"name" bson:"name"`
-	UserName  string             `json:"username" bson:"username"`
-	BirthDate types.Datetime     `json:"birthDate" bson:"birthDate"`
-	Password  types.Password     `json:"password" bson:"password"`
+	ID     primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	RoleID primitive.ObjectID `json:"_roleId" bson:"_roleId"`
+	// RoleIDs holds every role of the user, RoleID included. RoleID stays the
+	// primary role for clients that only know about one.
+	RoleIDs   []primitive.ObjectID `json:"_roleIds" bson:"_roleIds"`
+	Name      string               `json:"name" bson:"name"`
+	UserName  string               `json:"username" bson:"username"`
+	Email     string               `json:"email" bson:"email"`
+	BirthDate types.Datetime       `json:"birthDate" bson:"birthDate"`
+	Password  types.Password       `json:"password" bson:"password"`
+	Status    string               `json:"-" bson:"status"`
+
+	MFAEnabled    bool     `json:"-" bson:"mfaEnabled"`
+	MFASecret     string   `json:"-" bson:"mfaSecret"`
+	MFALastStep   int64    `json:"-" bson:"mfaLastStep"`
+	RecoveryCodes []string `json:"-" bson:"recoveryCodes"`
 }
 
 type Post struct {
 	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
 	UserID      primitive.ObjectID `json:"_userId" bson:"_userId"`
+	GroupID     primitive.ObjectID `json:"_groupId,omitempty" bson:"_groupId,omitempty"`
 	Title       string             `json:"title" bson:"title"`
 	Body        string             `json:"body" bson:"body"`
 	CreatedDate types.Datetime     `json:"createdDate" bson:"createdDate"`
+	// Status is one of the post lifecycle statuses; only published posts are
+	// public.
+	Status        string    `json:"status" bson:"status"`
+	PublishedDate time.Time `json:"publishedDate,omitempty" bson:"publishedDate,omitempty"`
+	// PublishAt schedules the post; the scheduler publishes it once due.
+	PublishAt time.Time `json:"publishAt,omitempty" bson:"publishAt,omitempty"`
+	// Revision is the number of the latest PostRevision.
+	Revision int `json:"revision" bson:"revision"`
+}
+
+// PostRevision is the content of a post after one of its updates. Revisions
+// are never changed; restoring one adds a new revision.
+type PostRevision struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	PostID       primitive.ObjectID `json:"_postId" bson:"_postId"`
+	Number       int                `json:"number" bson:"number"`
+	UserID       primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Title        string             `json:"title" bson:"title"`
+	Body         string             `json:"body" bson:"body"`
+	RestoredFrom int                `json:"restoredFrom,omitempty" bson:"restoredFrom,omitempty"`
+	CreatedDate  time.Time          `json:"createdDate" bson:"createdDate"`
+}
+
+// Group lets several users share posts. Each member holds a role that only
+// applies to the group's posts.
+type Group struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Name        string             `json:"name" bson:"name"`
+	Members     []GroupMember      `json:"members" bson:"members"`
+	CreatedDate types.Datetime     `json:"createdDate" bson:"createdDate"`
+}
+
+type GroupMember struct {
+	UserID primitive.ObjectID `json:"_userId" bson:"_userId"`
+	RoleID primitive.ObjectID `json:"_roleId" bson:"_roleId"`
 }
 
 type Migration struct {
@@ -36,8 +87,114 @@ type Migration struct {
 }
 
 type Session struct {
+	ID             primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID         primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Token          string             `json:"token" bson:"token"`
+	CreatedDate    types.Datetime     `json:"createdDate" bson:"createdDate"`
+	LastSeenDate   types.Datetime     `json:"lastSeenDate" bson:"lastSeenDate"`
+	ExpiresDate    time.Time          `json:"expiresDate" bson:"expiresDate"`
+	IP             string             `json:"ip" bson:"ip"`
+	UserAgent      string             `json:"userAgent" bson:"userAgent"`
+	Active         bool               `json:"active" bson:"active"`
+	Family         string             `json:"family" bson:"family"`
+	ClientID       string             `json:"clientId" bson:"clientId"`
+	Scopes         []string           `json:"scopes" bson:"scopes"`
+	ImpersonatorID primitive.ObjectID `json:"_impersonatorId,omitempty" bson:"_impersonatorId,omitempty"`
+}
+
+type RefreshToken struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Hash         string             `json:"hash" bson:"hash"`
+	Family       string             `json:"family" bson:"family"`
+	SessionToken string             `json:"sessionToken" bson:"sessionToken"`
+	ClientID     string             `json:"clientId" bson:"clientId"`
+	Scopes       []string           `json:"scopes" bson:"scopes"`
+	CreatedDate  types.Datetime     `json:"createdDate" bson:"createdDate"`
+	ExpiresDate  time.Time          `json:"expiresDate" bson:"expiresDate"`
+	Used         bool               `json:"used" bson:"used"`
+	Active       bool               `json:"active" bson:"active"`
+}
+
+type Client struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	ClientID     string             `json:"clientId" bson:"clientId"`
+	SecretHash   string             `json:"secretHash" bson:"secretHash"`
+	Name         string             `json:"name" bson:"name"`
+	RedirectURIs []string           `json:"redirectUris" bson:"redirectUris"`
+	GrantTypes   []string           `json:"grantTypes" bson:"grantTypes"`
+	Scopes       []string           `json:"scopes" bson:"scopes"`
+	Public       bool               `json:"public" bson:"public"`
+	OwnerID      primitive.ObjectID `json:"_ownerId" bson:"_ownerId"`
+	CreatedDate  types.Datetime     `json:"createdDate" bson:"createdDate"`
+}
+
+type AuthorizationCode struct {
+	ID                  primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID              primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Hash                string             `json:"hash" bson:"hash"`
+	ClientID            string             `json:"clientId" bson:"clientId"`
+	RedirectURI         string             `json:"redirectUri" bson:"redirectUri"`
+	Scopes              []string           `json:"scopes" bson:"scopes"`
+	CodeChallenge       string             `json:"codeChallenge" bson:"codeChallenge"`
+	CodeChallengeMethod string             `json:"codeChallengeMethod" bson:"codeChallengeMethod"`
+	Nonce               string             `json:"nonce" bson:"nonce"`
+	ExpiresDate         time.Time          `json:"expiresDate" bson:"expiresDate"`
+	Used                bool               `json:"used" bson:"used"`
+}
+
+type LoginAttempt struct {
+	ID              primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	Key             string             `json:"key" bson:"key"`
+	Failures        int                `json:"failures" bson:"failures"`
+	LastFailureDate time.Time          `json:"lastFailureDate" bson:"lastFailureDate"`
+	LockedUntil     time.Time          `json:"lockedUntil" bson:"lockedUntil"`
+	ExpiresDate     time.Time          `json:"expiresDate" bson:"expiresDate"`
+}
+
+type PasswordReset struct {
 	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
-	Token       string             `json:"token" bson:"token"`
+	UserID      primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Hash        string             `json:"hash" bson:"hash"`
 	CreatedDate types.Datetime     `json:"createdDate" bson:"createdDate"`
-	Active      bool               `json:"active" bson:"active"`
+	ExpiresDate time.Time          `json:"expiresDate" bson:"expiresDate"`
+	Used        bool               `json:"used" bson:"used"`
+}
+
+// EmailVerification confirms that the user owns Email. Verifications from
+// before email changes have no Email and only activate the account.
+type EmailVerification struct {
+	ID          primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID      primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Email       string             `json:"email,omitempty" bson:"email,omitempty"`
+	Hash        string             `json:"hash" bson:"hash"`
+	CreatedDate types.Datetime     `json:"createdDate" bson:"createdDate"`
+	ExpiresDate time.Time          `json:"expiresDate" bson:"expiresDate"`
+	Used        bool               `json:"used" bson:"used"`
+}
+
+// APIKey is a long-lived personal credential for automation. Only the hash of
+// the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID           primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	UserID       primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Name         string             `json:"name" bson:"name"`
+	Prefix       string             `json:"prefix" bson:"prefix"`
+	Hash         string             `json:"-" bson:"hash"`
+	Permissions  []string           `json:"permissions" bson:"permissions"`
+	CreatedDate  types.Datetime     `json:"createdDate" bson:"createdDate"`
+	LastUsedDate time.Time          `json:"lastUsedDate" bson:"lastUsedDate"`
+	ExpiresDate  time.Time          `json:"expiresDate" bson:"expiresDate"`
+	Active       bool               `json:"active" bson:"active"`
+}
+
+type AuditRecord struct {
+	ID             primitive.ObjectID `json:"_id,omitempty" bson:"_id,omitempty"`
+	ImpersonatorID primitive.ObjectID `json:"_impersonatorId" bson:"_impersonatorId"`
+	UserID         primitive.ObjectID `json:"_userId" bson:"_userId"`
+	Method         string             `json:"method" bson:"method"`
+	Path           string             `json:"path" bson:"path"`
+	Status         int                `json:"status" bson:"status"`
+	IP             string             `json:"ip" bson:"ip"`
+	CreatedDate    types.Datetime     `json:"createdDate" bson:"createdDate"`
 }
@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// compare implements the condition operators: eq, ne, in, contains, gt,
+// gte, lt, lte and exists. Numbers compare by value whatever their Go type.
+// A missing attribute fails every operator but exists, so two absent values
+// never match each other.
+func compare(operator string, actual interface{}, expected interface{}) bool {
+	if operator == "exists" {
+		return (actual != nil) == (expected != false)
+	}
+
+	if actual == nil || expected == nil {
+		return false
+	}
+
+	switch operator {
+	case "eq":
+		return equal(actual, expected)
+	case "ne":
+		return !equal(actual, expected)
+	case "in":
+		return contains(expected, actual)
+	case "contains":
+		return contains(actual, expected)
+	case "gt", "gte", "lt", "lte":
+		a, ok1 := toFloat(actual)
+		b, ok2 := toFloat(expected)
+
+		if !ok1 || !ok2 {
+			return false
+		}
+
+		switch operator {
+		case "gt":
+			return a > b
+		case "gte":
+			return a >= b
+		case "lt":
+			return a < b
+		default:
+			return a <= b
+		}
+	}
+
+	return false
+}
+
+func equal(a interface{}, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+
+		return ok && x == y
+	}
+
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func contains(list interface{}, item interface{}) bool {
+	value := reflect.ValueOf(list)
+
+	if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+		return false
+	}
+
+	for i := 0; i < value.Len(); i++ {
+		if equal(value.Index(i).Interface(), item) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	case float32:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,132 @@
+package policy
+
+import (
+	"strings"
+)
+
+const (
+	Allow         = "allow"
+	Deny          = "deny"
+	NotApplicable = "not_applicable"
+)
+
+// Rule allows or denies the actions it lists when all of its conditions hold.
+// Actions are permission patterns such as "post.delete.any" or "post.*".
+type Rule struct {
+	ID          string      `json:"id" bson:"id"`
+	Description string      `json:"description" bson:"description"`
+	Effect      string      `json:"effect" bson:"effect"`
+	Actions     []string    `json:"actions" bson:"actions"`
+	Conditions  []Condition `json:"conditions" bson:"conditions"`
+}
+
+// Condition compares an attribute such as "subject.roles",
+// "resource.ownerId" or "environment.hour" with Value, or with the attribute
+// named by ValueFrom.
+type Condition struct {
+	Attribute string      `json:"attribute" bson:"attribute"`
+	Operator  string      `json:"operator" bson:"operator"`
+	Value     interface{} `json:"value,omitempty" bson:"value,omitempty"`
+	ValueFrom string      `json:"valueFrom,omitempty" bson:"valueFrom,omitempty"`
+}
+
+type Request struct {
+	Subject     map[string]interface{} `json:"subject"`
+	Resource    map[string]interface{} `json:"resource"`
+	Action      string                 `json:"action"`
+	Environment map[string]interface{} `json:"environment"`
+}
+
+type Decision struct {
+	Effect string `json:"effect"`
+	Rule   string `json:"rule,omitempty"`
+}
+
+// Engine evaluates rules with deny-overrides: any matching deny rule wins,
+// then any matching allow rule. Match compares an action pattern with the
+// requested action.
+type Engine struct {
+	Rules []Rule
+	Match func(pattern string, action string) bool
+}
+
+func NewEngine(rules []Rule, match func(pattern string, action string) bool) *Engine {
+	return &Engine{
+		Rules: rules,
+		Match: match,
+	}
+}
+
+func (engine *Engine) Evaluate(request Request) Decision {
+	decision := Decision{Effect: NotApplicable}
+
+	for _, rule := range engine.Rules {
+		if !engine.matchesAction(rule, request.Action) || !matchesConditions(rule.Conditions, request) {
+			continue
+		}
+
+		if rule.Effect == Deny {
+			return Decision{Effect: Deny, Rule: rule.ID}
+		}
+
+		if rule.Effect == Allow && decision.Effect == NotApplicable {
+			decision = Decision{Effect: Allow, Rule: rule.ID}
+		}
+	}
+
+	return decision
+}
+
+func (engine *Engine) matchesAction(rule Rule, action string) bool {
+	for _, pattern := range rule.Actions {
+		if engine.Match != nil && engine.Match(pattern, action) || pattern == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesConditions(conditions []Condition, request Request) bool {
+	for _, condition := range conditions {
+		actual, _ := request.Attribute(condition.Attribute)
+		expected := condition.Value
+
+		if condition.ValueFrom != "" {
+			expected, _ = request.Attribute(condition.ValueFrom)
+		}
+
+		if !compare(condition.Operator, actual, expected) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Attribute resolves a dotted name against the subject, resource or
+// environment of the request.
+func (request Request) Attribute(name string) (interface{}, bool) {
+	parts := strings.SplitN(name, ".", 2)
+
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	var attributes map[string]interface{}
+
+	switch parts[0] {
+	case "subject":
+		attributes = request.Subject
+	case "resource":
+		attributes = request.Resource
+	case "environment":
+		attributes = request.Environment
+	case "action":
+		return request.Action, true
+	}
+
+	value, ok := attributes[parts[1]]
+
+	return value, ok
+}
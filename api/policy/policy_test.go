@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func match(pattern string, action string) bool {
+	return pattern == "*" || strings.HasSuffix(pattern, ".*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*"))
+}
+
+func TestEvaluate(t *testing.T) {
+	engine := NewEngine([]Rule{
+		{
+			ID:      "night-deletes",
+			Effect:  Deny,
+			Actions: []string{"post.*"},
+			Conditions: []Condition{
+				{Attribute: "environment.hour", Operator: "lt", Value: float64(6)},
+			},
+		},
+		{
+			ID:      "editors",
+			Effect:  Allow,
+			Actions: []string{"post.update"},
+			Conditions: []Condition{
+				{Attribute: "subject.roles", Operator: "contains", Value: "Editor"},
+			},
+		},
+		{
+			ID:      "owners",
+			Effect:  Allow,
+			Actions: []string{"post.delete"},
+			Conditions: []Condition{
+				{Attribute: "resource.ownerId", Operator: "eq", ValueFrom: "subject.id"},
+			},
+		},
+	}, match)
+
+	request := Request{
+		Subject:     map[string]interface{}{"id": "u1", "roles": []string{"User", "Editor"}},
+		Resource:    map[string]interface{}{"ownerId": "u2"},
+		Action:      "post.update",
+		Environment: map[string]interface{}{"hour": 12},
+	}
+
+	if decision := engine.Evaluate(request); decision.Effect == Allow && decision.Rule == "editors" {
+		t.Log("Evaluate 01 passed")
+	} else {
+		t.Error("Evaluate 01 failed")
+	}
+
+	request.Environment["hour"] = 3
+
+	if decision := engine.Evaluate(request); decision.Effect == Deny && decision.Rule == "night-deletes" {
+		t.Log("Evaluate 02 passed")
+	} else {
+		t.Error("Evaluate 02 failed")
+	}
+
+	request.Environment["hour"] = 12
+	request.Action = "post.delete"
+
+	if decision := engine.Evaluate(request); decision.Effect == NotApplicable {
+		t.Log("Evaluate 03 passed")
+	} else {
+		t.Error("Evaluate 03 failed")
+	}
+
+	request.Resource["ownerId"] = "u1"
+
+	if decision := engine.Evaluate(request); decision.Effect == Allow && decision.Rule == "owners" {
+		t.Log("Evaluate 04 passed")
+	} else {
+		t.Error("Evaluate 04 failed")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	if compare("in", "Admin", []interface{}{"Admin", "Editor"}) && !compare("in", "User", []interface{}{"Admin"}) {
+		t.Log("Compare 01 passed")
+	} else {
+		t.Error("Compare 01 failed")
+	}
+
+	if compare("gte", 9, float64(9)) && !compare("gt", "9", 1) {
+		t.Log("Compare 02 passed")
+	} else {
+		t.Error("Compare 02 failed")
+	}
+
+	if compare("exists", "x", nil) && !compare("exists", nil, nil) && compare("exists", nil, false) {
+		t.Log("Compare 03 passed")
+	} else {
+		t.Error("Compare 03 failed")
+	}
+
+	if !compare("eq", nil, nil) && !compare("ne", nil, "x") && !compare("in", nil, []interface{}{nil}) {
+		t.Log("Compare 04 passed")
+	} else {
+		t.Error("Compare 04 failed")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+
+	os.WriteFile(path, []byte(`[{"id": "r1", "effect": "deny", "actions": ["*"], "conditions": [{"attribute": "subject.mfa", "operator": "eq", "value": false}]}]`), 0600)
+
+	rules, err := LoadFile(path)
+
+	if err == nil && len(rules) == 1 && rules[0].Effect == Deny && rules[0].Conditions[0].Value == false {
+		t.Log("LoadFile 01 passed")
+	} else {
+		t.Error("LoadFile 01 failed")
+	}
+}
@@ -0,0 +1,80 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type Source interface {
+	Rules() ([]Rule, error)
+}
+
+// StaticSource serves rules loaded once, such as those of a policy file.
+type StaticSource struct {
+	Items []Rule
+}
+
+func (source *StaticSource) Rules() ([]Rule, error) {
+	return source.Items, nil
+}
+
+// LoadFile reads a JSON array of rules.
+func LoadFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []Rule{}
+
+	err = json.Unmarshal(data, &rules)
+
+	return rules, err
+}
+
+// CollectionSource reads rules from the policies collection, so they can be
+// changed without a restart.
+type CollectionSource struct {
+	Connection *mongo.Database
+}
+
+func (source *CollectionSource) Rules() ([]Rule, error) {
+	rules := []Rule{}
+
+	cur, err := source.Connection.Collection("policies").Find(context.TODO(), bson.M{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(context.TODO())
+
+	err = cur.All(context.TODO(), &rules)
+
+	return rules, err
+}
+
+// NewSource picks where rules come from with POLICY_SOURCE: "file" reads
+// POLICY_FILE once, "collection" reads the policies collection on every
+// request, and anything else disables policies.
+func NewSource(connection *mongo.Database) (Source, error) {
+	switch os.Getenv("POLICY_SOURCE") {
+	case "file":
+		rules, err := LoadFile(os.Getenv("POLICY_FILE"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &StaticSource{Items: rules}, nil
+	case "collection":
+		return &CollectionSource{Connection: connection}, nil
+	}
+
+	return &StaticSource{Items: []Rule{}}, nil
+}
@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
+)
+
+func QueryAPIKeys(connection *mongo.Database, filter bson.M) ([]models.APIKey, error, int) {
+	var apiKeys []models.APIKey = []models.APIKey{}
+
+	cur, err := connection.Collection("api_keys").Find(context.TODO(), filter)
+
+	if err != nil {
+		return []models.APIKey{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var apiKey models.APIKey
+		err := cur.Decode(&apiKey)
+
+		if err != nil {
+			return []models.APIKey{}, err, constants.InternalServerError
+		}
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.APIKey{}, err, constants.InternalServerError
+	}
+
+	return apiKeys, err, constants.Success
+}
+
+func QueryAPIKey(connection *mongo.Database, filter bson.M) (models.APIKey, error, int) {
+	var apiKey models.APIKey
+
+	err := connection.Collection("api_keys").FindOne(context.TODO(), filter).Decode(&apiKey)
+
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("API key doesn't exist"), constants.NotFound
+	}
+
+	return apiKey, err, constants.Success
+}
+
+func InsertAPIKey(connection *mongo.Database, apiKey models.APIKey) (primitive.ObjectID, error) {
+	result, err := connection.Collection("api_keys").InsertOne(context.TODO(), apiKey)
+
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	id, _ := result.InsertedID.(primitive.ObjectID)
+
+	return id, nil
+}
+
+func GetUserAPIKeys(connection *mongo.Database, userId primitive.ObjectID) ([]serializers.APIKey, error, int) {
+	apiKeys, err, status := QueryAPIKeys(connection, bson.M{"_userId": userId})
+
+	if err != nil {
+		return []serializers.APIKey{}, err, status
+	}
+
+	return serializers.SerializeManyAPIKeys(apiKeys), err, status
+}
+
+// CreateAPIKey stores a key whose permissions were already checked against
+// the user's roles. The plain key is returned once, in the serializer.
+func CreateAPIKey(connection *mongo.Database, apiKey models.APIKey, key string) (serializers.APIKey, error, int) {
+	if apiKey.Name == "" {
+		return serializers.APIKey{}, fmt.Errorf("API key name is required"), constants.UnprocessableEntity
+	}
+
+	if len(apiKey.Permissions) == 0 {
+		return serializers.APIKey{}, fmt.Errorf("API key permissions is required"), constants.UnprocessableEntity
+	}
+
+	if err := constants.ValidatePermissions(apiKey.Permissions); err != nil {
+		return serializers.APIKey{}, err, constants.UnprocessableEntity
+	}
+
+	if !apiKey.ExpiresDate.IsZero() && apiKey.ExpiresDate.Before(time.Now()) {
+		return serializers.APIKey{}, fmt.Errorf("API key expiresDate must be in the future"), constants.UnprocessableEntity
+	}
+
+	apiKey.CreatedDate.Time = time.Now()
+	apiKey.Active = true
+
+	id, err := InsertAPIKey(connection, apiKey)
+
+	if err != nil {
+		return serializers.APIKey{}, err, constants.BadRequest
+	}
+
+	apiKey.ID = id
+
+	serialized := serializers.SerializeOneAPIKey(apiKey)
+	serialized.Key = key
+
+	return serialized, nil, constants.Success
+}
+
+func TouchAPIKey(connection *mongo.Database, id primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"lastUsedDate": time.Now(),
+		},
+	}
+
+	_, err := connection.Collection("api_keys").UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+
+	return err
+}
+
+func RevokeAPIKey(connection *mongo.Database, userId primitive.ObjectID, idParam string) (serializers.APIKey, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	update := bson.M{
+		"$set": bson.M{
+			"active": false,
+		},
+	}
+
+	result, err := connection.Collection("api_keys").UpdateOne(context.TODO(), bson.M{"_id": id, "_userId": userId}, update)
+
+	if err != nil {
+		return serializers.APIKey{}, err, constants.InternalServerError
+	}
+
+	if result.MatchedCount == 0 {
+		return serializers.APIKey{}, fmt.Errorf("Requested API key doesn't exist"), constants.NotFound
+	}
+
+	return serializers.APIKey{}, nil, constants.Success
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
+)
+
+func QueryAuditRecords(connection *mongo.Database, filter bson.M) ([]models.AuditRecord, error, int) {
+	var records []models.AuditRecord = []models.AuditRecord{}
+
+	opts := options.Find().SetSort(bson.M{"createdDate": -1})
+
+	cur, err := connection.Collection("audit").Find(context.TODO(), filter, opts)
+
+	if err != nil {
+		return []models.AuditRecord{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var record models.AuditRecord
+		err := cur.Decode(&record)
+
+		if err != nil {
+			return []models.AuditRecord{}, err, constants.InternalServerError
+		}
+
+		records = append(records, record)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.AuditRecord{}, err, constants.InternalServerError
+	}
+
+	return records, err, constants.Success
+}
+
+func InsertAuditRecord(connection *mongo.Database, record models.AuditRecord) error {
+	record.CreatedDate.Time = time.Now()
+
+	_, err := connection.Collection("audit").InsertOne(context.TODO(), record)
+
+	return err
+}
+
+// GetImpersonationRecords lists the requests made while userId was either
+// impersonating someone or being impersonated, newest first.
+func GetImpersonationRecords(connection *mongo.Database, userId primitive.ObjectID) ([]serializers.AuditRecord, error, int) {
+	records, err, status := QueryAuditRecords(connection, bson.M{
+		"$or": []bson.M{
+			{"_userId": userId},
+			{"_impersonatorId": userId},
+		},
+	})
+
+	if err != nil {
+		return []serializers.AuditRecord{}, err, status
+	}
+
+	return serializers.SerializeManyAuditRecords(records), nil, constants.Success
+}
@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+)
+
+func InsertAuthorizationCode(connection *mongo.Database, code models.AuthorizationCode) error {
+	_, err := connection.Collection("authorization_codes").InsertOne(context.TODO(), code)
+
+	return err
+}
+
+// UseAuthorizationCode marks the code as used and returns it. A code can only
+// be redeemed once, so a second call for the same hash fails.
+func UseAuthorizationCode(connection *mongo.Database, hash string) (models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	err := connection.Collection("authorization_codes").FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"hash": hash, "used": false},
+		update,
+	).Decode(&code)
+
+	if err != nil {
+		return models.AuthorizationCode{}, fmt.Errorf("Authorization code doesn't exist")
+	}
+
+	return code, err
+}
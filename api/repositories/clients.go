@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
+)
+
+var GrantTypes = []string{"authorization_code", "refresh_token", "client_credentials"}
+
+func QueryClients(connection *mongo.Database, filter bson.M) ([]models.Client, error, int) {
+	var clients []models.Client = []models.Client{}
+
+	cur, err := connection.Collection("clients").Find(context.TODO(), filter)
+
+	if err != nil {
+		return []models.Client{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var client models.Client
+		err := cur.Decode(&client)
+
+		if err != nil {
+			return []models.Client{}, err, constants.InternalServerError
+		}
+
+		clients = append(clients, client)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.Client{}, err, constants.InternalServerError
+	}
+
+	return clients, err, constants.Success
+}
+
+func QueryClient(connection *mongo.Database, filter bson.M) (models.Client, error, int) {
+	var client models.Client
+
+	err := connection.Collection("clients").FindOne(context.TODO(), filter).Decode(&client)
+
+	if err != nil {
+		return models.Client{}, fmt.Errorf("Client doesn't exist"), constants.NotFound
+	}
+
+	return client, err, constants.Success
+}
+
+func InsertClient(connection *mongo.Database, client models.Client) error {
+	_, err := connection.Collection("clients").InsertOne(context.TODO(), client)
+
+	return err
+}
+
+func GetClients(connection *mongo.Database) ([]serializers.Client, error, int) {
+	clients, err, status := QueryClients(connection, bson.M{})
+
+	if err != nil {
+		return []serializers.Client{}, err, status
+	}
+
+	return serializers.SerializeManyClients(clients), err, status
+}
+
+// CreateClient registers client for its owner. Scopes must be checked
+// against the owner's permissions by the caller.
+func CreateClient(connection *mongo.Database, client models.Client, clientId string, secretHash string) (serializers.Client, error, int) {
+	if client.Name == "" {
+		return serializers.Client{}, fmt.Errorf("Client name is required"), constants.UnprocessableEntity
+	}
+
+	if len(client.GrantTypes) == 0 {
+		return serializers.Client{}, fmt.Errorf("Client grantTypes is required"), constants.UnprocessableEntity
+	}
+
+	for _, grantType := range client.GrantTypes {
+		found := false
+
+		for _, supported := range GrantTypes {
+			if grantType == supported {
+				found = true
+			}
+		}
+
+		if !found {
+			return serializers.Client{}, fmt.Errorf("Client grant type %s is not supported", grantType), constants.UnprocessableEntity
+		}
+
+		if grantType == "authorization_code" && len(client.RedirectURIs) == 0 {
+			return serializers.Client{}, fmt.Errorf("Client redirectUris is required"), constants.UnprocessableEntity
+		}
+
+		if grantType == "client_credentials" && client.Public {
+			return serializers.Client{}, fmt.Errorf("Public clients can't use client_credentials"), constants.UnprocessableEntity
+		}
+	}
+
+	if client.Scopes == nil {
+		client.Scopes = []string{}
+	}
+
+	client.ClientID = clientId
+	client.SecretHash = secretHash
+	client.CreatedDate.Time = time.Now()
+
+	if client.Public {
+		client.SecretHash = ""
+	}
+
+	err := InsertClient(connection, client)
+
+	if err != nil {
+		return serializers.Client{}, err, constants.BadRequest
+	}
+
+	client, err, status := QueryClient(connection, bson.M{"clientId": clientId})
+
+	if err != nil {
+		return serializers.Client{}, err, status
+	}
+
+	return serializers.SerializeOneClient(client), err, constants.Success
+}
+
+func GetClient(connection *mongo.Database, idParam string) (serializers.Client, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	client, err, status := QueryClient(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Client{}, err, status
+	}
+
+	return serializers.SerializeOneClient(client), err, status
+}
+
+func DeleteClient(connection *mongo.Database, idParam string) (serializers.Client, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	result, err := connection.Collection("clients").DeleteOne(context.TODO(), bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Client{}, err, constants.BadRequest
+	}
+
+	if result.DeletedCount == 0 {
+		return serializers.Client{}, fmt.Errorf("Requested Client doesn't exist"), constants.NotFound
+	}
+
+	return serializers.Client{}, err, constants.Success
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+)
+
+func InsertEmailVerification(connection *mongo.Database, verification models.EmailVerification) error {
+	_, err := connection.Collection("email_verifications").InsertOne(context.TODO(), verification)
+
+	return err
+}
+
+// StartEmailVerification stores a new verification token for the user and
+// the email it is sent to, and invalidates the previous ones, so only the
+// latest email works.
+func StartEmailVerification(connection *mongo.Database, userId primitive.ObjectID, email string, hash string, duration time.Duration) (models.EmailVerification, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	_, err := connection.Collection("email_verifications").UpdateMany(context.TODO(), bson.M{"_userId": userId, "used": false}, update)
+
+	if err != nil {
+		return models.EmailVerification{}, err
+	}
+
+	var verification models.EmailVerification
+
+	verification.UserID = userId
+	verification.Email = email
+	verification.Hash = hash
+	verification.CreatedDate.Time = time.Now()
+	verification.ExpiresDate = verification.CreatedDate.Time.Add(duration)
+
+	err = InsertEmailVerification(connection, verification)
+
+	if err != nil {
+		return models.EmailVerification{}, err
+	}
+
+	return verification, err
+}
+
+func UseEmailVerification(connection *mongo.Database, hash string) (models.EmailVerification, error) {
+	var verification models.EmailVerification
+
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	err := connection.Collection("email_verifications").FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"hash": hash, "used": false},
+		update,
+	).Decode(&verification)
+
+	if err != nil {
+		return models.EmailVerification{}, fmt.Errorf("Invalid or expired verification token")
+	}
+
+	if time.Now().After(verification.ExpiresDate) {
+		return models.EmailVerification{}, fmt.Errorf("Invalid or expired verification token")
+	}
+
+	return verification, err
+}
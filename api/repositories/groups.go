@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
+)
+
+func QueryGroups(connection *mongo.Database, filter bson.M) ([]models.Group, error, int) {
+	var groups []models.Group = []models.Group{}
+
+	cur, err := connection.Collection("groups").Find(context.TODO(), filter)
+
+	if err != nil {
+		return []models.Group{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var group models.Group
+		err := cur.Decode(&group)
+
+		if err != nil {
+			return []models.Group{}, err, constants.InternalServerError
+		}
+
+		groups = append(groups, group)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.Group{}, err, constants.InternalServerError
+	}
+
+	return groups, err, constants.Success
+}
+
+func QueryGroup(connection *mongo.Database, filter bson.M) (models.Group, error, int) {
+	var group models.Group
+
+	err := connection.Collection("groups").FindOne(context.TODO(), filter).Decode(&group)
+
+	if err != nil {
+		return models.Group{}, fmt.Errorf("Group doesn't exist"), constants.NotFound
+	}
+
+	return group, err, constants.Success
+}
+
+func InsertGroup(connection *mongo.Database, group models.Group) (primitive.ObjectID, error) {
+	result, err := connection.Collection("groups").InsertOne(context.TODO(), group)
+
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	id, _ := result.InsertedID.(primitive.ObjectID)
+
+	return id, nil
+}
+
+func GetGroups(connection *mongo.Database) ([]serializers.Group, error, int) {
+	groups, err, status := QueryGroups(connection, bson.M{})
+
+	if err != nil {
+		return []serializers.Group{}, err, status
+	}
+
+	return serializers.SerializeManyGroups(groups), err, status
+}
+
+// CreateGroup inserts group. The roles of its members must be checked
+// against the caller by the controller.
+func CreateGroup(connection *mongo.Database, group models.Group) (serializers.Group, error, int) {
+	if group.Name == "" {
+		return serializers.Group{}, fmt.Errorf("Group name is required"), constants.UnprocessableEntity
+	}
+
+	groups, _, _ := QueryGroups(connection, bson.M{"name": group.Name})
+
+	if len(groups) > 0 {
+		return serializers.Group{}, fmt.Errorf("Group name must be unique"), constants.UnprocessableEntity
+	}
+
+	if group.Members == nil {
+		group.Members = []models.GroupMember{}
+	}
+
+	for _, member := range group.Members {
+		if err := validateGroupMember(connection, member); err != nil {
+			return serializers.Group{}, err, constants.UnprocessableEntity
+		}
+	}
+
+	group.ID = primitive.NilObjectID
+	group.CreatedDate.Time = time.Now()
+
+	id, err := InsertGroup(connection, group)
+
+	if err != nil {
+		return serializers.Group{}, err, constants.BadRequest
+	}
+
+	group, err, status := QueryGroup(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Group{}, err, status
+	}
+
+	return serializers.SerializeOneGroup(group), err, constants.Success
+}
+
+func GetGroup(connection *mongo.Database, idParam string) (serializers.Group, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	group, err, status := QueryGroup(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Group{}, err, status
+	}
+
+	return serializers.SerializeOneGroup(group), err, status
+}
+
+func UpdateGroup(connection *mongo.Database, idParam string, body io.Reader) (serializers.Group, error, int) {
+	var group models.Group
+
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	_ = json.NewDecoder(body).Decode(&group)
+
+	_, err, _ := QueryGroup(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Group{}, fmt.Errorf("Requested Group doesn't exist"), constants.NotFound
+	}
+
+	groups, _, _ := QueryGroups(connection, bson.M{"name": group.Name})
+
+	if len(groups) > 0 && groups[0].ID != id {
+		return serializers.Group{}, fmt.Errorf("A Group with this name already exists"), constants.UnprocessableEntity
+	}
+
+	setObj := bson.M{}
+
+	if group.Name != "" {
+		setObj["name"] = group.Name
+	}
+
+	update := bson.M{
+		"$set": setObj,
+	}
+
+	_, err = connection.Collection("groups").UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+
+	if err != nil {
+		return serializers.Group{}, err, constants.UnprocessableEntity
+	}
+
+	return GetGroup(connection, idParam)
+}
+
+// DeleteGroup removes the group; its posts go back to their authors.
+func DeleteGroup(connection *mongo.Database, idParam string) (serializers.Group, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	result, err := connection.Collection("groups").DeleteOne(context.TODO(), bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Group{}, err, constants.BadRequest
+	}
+
+	if result.DeletedCount == 0 {
+		return serializers.Group{}, fmt.Errorf("Requested Group doesn't exist"), constants.NotFound
+	}
+
+	_, err = connection.Collection("posts").UpdateMany(context.TODO(), bson.M{"_groupId": id}, bson.M{
+		"$unset": bson.M{
+			"_groupId": "",
+		},
+	})
+
+	if err != nil {
+		return serializers.Group{}, err, constants.InternalServerError
+	}
+
+	return serializers.Group{}, err, constants.Success
+}
+
+// SetGroupMember adds the user to the group, or changes their group role.
+func SetGroupMember(connection *mongo.Database, idParam string, member models.GroupMember) (serializers.Group, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	_, err, _ := QueryGroup(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Group{}, fmt.Errorf("Requested Group doesn't exist"), constants.NotFound
+	}
+
+	if err := validateGroupMember(connection, member); err != nil {
+		return serializers.Group{}, err, constants.UnprocessableEntity
+	}
+
+	_, err = connection.Collection("groups").UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{
+		"$pull": bson.M{
+			"members": bson.M{"_userId": member.UserID},
+		},
+	})
+
+	if err != nil {
+		return serializers.Group{}, err, constants.InternalServerError
+	}
+
+	_, err = connection.Collection("groups").UpdateOne(context.TODO(), bson.M{"_id": id}, bson.M{
+		"$push": bson.M{
+			"members": member,
+		},
+	})
+
+	if err != nil {
+		return serializers.Group{}, err, constants.InternalServerError
+	}
+
+	return GetGroup(connection, idParam)
+}
+
+func RemoveGroupMember(connection *mongo.Database, idParam string, userIdParam string) (serializers.Group, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+	userId, _ := primitive.ObjectIDFromHex(userIdParam)
+
+	result, err := connection.Collection("groups").UpdateOne(context.TODO(), bson.M{"_id": id, "members._userId": userId}, bson.M{
+		"$pull": bson.M{
+			"members": bson.M{"_userId": userId},
+		},
+	})
+
+	if err != nil {
+		return serializers.Group{}, err, constants.InternalServerError
+	}
+
+	if result.MatchedCount == 0 {
+		return serializers.Group{}, fmt.Errorf("Requested Group member doesn't exist"), constants.NotFound
+	}
+
+	return GetGroup(connection, idParam)
+}
+
+func GetGroupPosts(connection *mongo.Database, idParam string, visibility bson.M) ([]serializers.Post, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	group, err, status := QueryGroup(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return []serializers.Post{}, err, status
+	}
+
+	posts, err, _ := QueryPosts(connection, bson.M{"$and": []bson.M{{"_groupId": group.ID}, visibility}})
+
+	if err != nil {
+		return []serializers.Post{}, err, constants.InternalServerError
+	}
+
+	return serializers.SerializeManyPosts(posts), err, constants.Success
+}
+
+// GetGroupMember returns the membership of the user in the group, if any.
+func GetGroupMember(group models.Group, userId primitive.ObjectID) (models.GroupMember, bool) {
+	for _, member := range group.Members {
+		if member.UserID == userId {
+			return member, true
+		}
+	}
+
+	return models.GroupMember{}, false
+}
+
+func validateGroupMember(connection *mongo.Database, member models.GroupMember) error {
+	_, err, _ := QueryUser(connection, bson.M{"_id": member.UserID})
+
+	if err != nil {
+		return fmt.Errorf("Group member User doesn't exist")
+	}
+
+	_, err, _ = QueryRole(connection, bson.M{"_id": member.RoleID})
+
+	if err != nil {
+		return fmt.Errorf("Group member Role doesn't exist")
+	}
+
+	return nil
+}
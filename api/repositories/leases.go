@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AcquireLease takes or renews the named lease for owner. It fails while
+// another owner holds an unexpired lease: the upsert then collides with the
+// existing document instead of replacing it.
+func AcquireLease(connection *mongo.Database, name string, owner string, duration time.Duration) (bool, error) {
+	now := time.Now()
+
+	filter := bson.M{
+		"_id": name,
+		"$or": []bson.M{
+			{"owner": owner},
+			{"expiresDate": bson.M{"$lt": now}},
+		},
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"owner":       owner,
+			"expiresDate": now.Add(duration),
+		},
+	}
+
+	_, err := connection.Collection("leases").UpdateOne(context.TODO(), filter, update, options.Update().SetUpsert(true))
+
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ReleaseLease lets another owner take the lease right away.
+func ReleaseLease(connection *mongo.Database, name string, owner string) error {
+	_, err := connection.Collection("leases").DeleteOne(context.TODO(), bson.M{"_id": name, "owner": owner})
+
+	return err
+}
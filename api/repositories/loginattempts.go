@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+)
+
+func QueryLoginAttempt(connection *mongo.Database, key string) (models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+
+	err := connection.Collection("login_attempts").FindOne(context.TODO(), bson.M{"key": key}).Decode(&attempt)
+
+	if err != nil {
+		return models.LoginAttempt{}, fmt.Errorf("Login attempt doesn't exist")
+	}
+
+	return attempt, err
+}
+
+// IncrementLoginFailures counts one more failure for key and returns the
+// updated record. Counters are forgotten after retention without failures.
+func IncrementLoginFailures(connection *mongo.Database, key string, retention time.Duration) (models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+
+	now := time.Now()
+
+	update := bson.M{
+		"$inc": bson.M{
+			"failures": 1,
+		},
+		"$set": bson.M{
+			"lastFailureDate": now,
+			"expiresDate":     now.Add(retention),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	err := connection.Collection("login_attempts").FindOneAndUpdate(context.TODO(), bson.M{"key": key}, update, opts).Decode(&attempt)
+
+	if err != nil {
+		return models.LoginAttempt{}, err
+	}
+
+	return attempt, err
+}
+
+func LockLoginAttempt(connection *mongo.Database, key string, until time.Time) error {
+	update := bson.M{
+		"$set": bson.M{
+			"lockedUntil": until,
+		},
+	}
+
+	_, err := connection.Collection("login_attempts").UpdateOne(context.TODO(), bson.M{"key": key}, update)
+
+	return err
+}
+
+func ClearLoginAttempt(connection *mongo.Database, key string) error {
+	_, err := connection.Collection("login_attempts").DeleteOne(context.TODO(), bson.M{"key": key})
+
+	return err
+}
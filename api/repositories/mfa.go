@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func SetUserMFASecret(connection *mongo.Database, userId primitive.ObjectID, secret string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"mfaEnabled":    false,
+			"mfaSecret":     secret,
+			"recoveryCodes": []string{},
+		},
+	}
+
+	_, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": userId}, update)
+
+	return err
+}
+
+func EnableUserMFA(connection *mongo.Database, userId primitive.ObjectID, recoveryCodes []string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"mfaEnabled":    true,
+			"recoveryCodes": recoveryCodes,
+		},
+	}
+
+	_, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": userId}, update)
+
+	return err
+}
+
+func DisableUserMFA(connection *mongo.Database, userId primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"mfaEnabled":    false,
+			"mfaSecret":     "",
+			"recoveryCodes": []string{},
+		},
+	}
+
+	_, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": userId}, update)
+
+	return err
+}
+
+// UseUserMFAStep records the TOTP time step a code was accepted for, failing
+// when that step (or a later one) was already used, so a code can't be
+// replayed within its validity window.
+func UseUserMFAStep(connection *mongo.Database, userId primitive.ObjectID, step int64) error {
+	update := bson.M{
+		"$set": bson.M{
+			"mfaLastStep": step,
+		},
+	}
+
+	filter := bson.M{
+		"_id": userId,
+		"$or": []bson.M{
+			{"mfaLastStep": bson.M{"$lt": step}},
+			{"mfaLastStep": bson.M{"$exists": false}},
+		},
+	}
+
+	result, err := connection.Collection("users").UpdateOne(context.TODO(), filter, update)
+
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return fmt.Errorf("Code already used")
+	}
+
+	return err
+}
+
+func UseUserRecoveryCode(connection *mongo.Database, userId primitive.ObjectID, hash string) error {
+	update := bson.M{
+		"$pull": bson.M{
+			"recoveryCodes": hash,
+		},
+	}
+
+	result, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": userId, "recoveryCodes": hash}, update)
+
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return fmt.Errorf("Invalid recovery code")
+	}
+
+	return err
+}
@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+
+	mongobson "go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	types "auth_blog_service/types"
+)
+
+// pageCursor points after the last document of a page. It keeps the sort it
+// was made for, since a cursor means nothing under another order.
+type pageCursor struct {
+	Sort  string             `bson:"s"`
+	Value mongobson.RawValue `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// findPage runs a keyset-paginated find. sortFields maps the sort keys the
+// list accepts to document fields; "_id" is the tie breaker and the default.
+// decode is called for each document of the page.
+func findPage(connection *mongo.Database, collection string, filter bson.M, query types.ListQuery, sortFields map[string]string, decode func(cur *mongo.Cursor) error) (types.Page, error, int) {
+	field, direction, err := parseSort(query.Sort, sortFields)
+
+	if err != nil {
+		return types.Page{}, err, constants.UnprocessableEntity
+	}
+
+	total, err := connection.Collection(collection).CountDocuments(context.TODO(), filter)
+
+	if err != nil {
+		return types.Page{}, err, constants.InternalServerError
+	}
+
+	pageFilter := filter
+
+	if query.Cursor != "" {
+		cursor, err := decodePageCursor(query.Cursor)
+
+		if err != nil || cursor.Sort != query.Sort {
+			return types.Page{}, fmt.Errorf("Invalid cursor"), constants.UnprocessableEntity
+		}
+
+		pageFilter = bson.M{"$and": []bson.M{filter, keysetFilter(field, direction, cursor)}}
+	}
+
+	sort := primitive.D{{Key: field, Value: direction}}
+
+	if field != "_id" {
+		sort = append(sort, primitive.E{Key: "_id", Value: direction})
+	}
+
+	opts := options.Find().SetSort(sort).SetLimit(query.Limit + 1)
+
+	cur, err := connection.Collection(collection).Find(context.TODO(), pageFilter, opts)
+
+	if err != nil {
+		return types.Page{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	var last mongobson.Raw
+	var count int64
+
+	page := types.Page{Total: total}
+
+	for cur.Next(context.TODO()) {
+		if count == query.Limit {
+			page.Next, err = encodePageCursor(query.Sort, field, last)
+
+			if err != nil {
+				return types.Page{}, err, constants.InternalServerError
+			}
+
+			break
+		}
+
+		if err := decode(cur); err != nil {
+			return types.Page{}, err, constants.InternalServerError
+		}
+
+		last = append(mongobson.Raw{}, cur.Current...)
+		count++
+	}
+
+	if err := cur.Err(); err != nil {
+		return types.Page{}, err, constants.InternalServerError
+	}
+
+	return page, nil, constants.Success
+}
+
+// parseSort turns "title" or "-title" into a field and a direction.
+func parseSort(sort string, sortFields map[string]string) (string, int, error) {
+	if sort == "" {
+		return "_id", 1, nil
+	}
+
+	direction := 1
+
+	if strings.HasPrefix(sort, "-") {
+		direction = -1
+		sort = strings.TrimPrefix(sort, "-")
+	}
+
+	field, ok := sortFields[sort]
+
+	if !ok {
+		return "", 0, fmt.Errorf("Can't sort by %s", sort)
+	}
+
+	return field, direction, nil
+}
+
+func keysetFilter(field string, direction int, cursor pageCursor) bson.M {
+	operator := "$gt"
+
+	if direction < 0 {
+		operator = "$lt"
+	}
+
+	if field == "_id" {
+		return bson.M{"_id": bson.M{operator: cursor.ID}}
+	}
+
+	return bson.M{
+		"$or": []bson.M{
+			{field: bson.M{operator: cursor.Value}},
+			{field: cursor.Value, "_id": bson.M{operator: cursor.ID}},
+		},
+	}
+}
+
+func encodePageCursor(sort string, field string, last mongobson.Raw) (string, error) {
+	cursor := pageCursor{
+		Sort:  sort,
+		Value: mongobson.RawValue{Type: bsontype.Null},
+		ID:    last.Lookup("_id").ObjectID(),
+	}
+
+	if value, err := last.LookupErr(strings.Split(field, ".")...); err == nil {
+		cursor.Value = value
+	}
+
+	raw, err := mongobson.Marshal(cursor)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodePageCursor(encoded string) (pageCursor, error) {
+	var cursor pageCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+
+	if err != nil {
+		return pageCursor{}, err
+	}
+
+	err = mongobson.Unmarshal(raw, &cursor)
+
+	return cursor, err
+}
+
+// prefixFilter matches values starting with prefix, ignoring case.
+func prefixFilter(prefix string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(prefix), "$options": "i"}
+}
+
+// createdFilter bounds a date field to the created range of the query.
+func createdFilter(filter bson.M, field string, query types.ListQuery) {
+	bounds := bson.M{}
+
+	if !query.CreatedFrom.IsZero() {
+		bounds["$gte"] = query.CreatedFrom
+	}
+
+	if !query.CreatedTo.IsZero() {
+		bounds["$lt"] = query.CreatedTo
+	}
+
+	if len(bounds) > 0 {
+		filter[field] = bounds
+	}
+}
+
+// createdIDFilter bounds documents without a creation date by the timestamp
+// of their ObjectID.
+func createdIDFilter(filter bson.M, query types.ListQuery) {
+	bounds := bson.M{}
+
+	if !query.CreatedFrom.IsZero() {
+		bounds["$gte"] = primitive.NewObjectIDFromTimestamp(query.CreatedFrom)
+	}
+
+	if !query.CreatedTo.IsZero() {
+		bounds["$lt"] = primitive.NewObjectIDFromTimestamp(query.CreatedTo)
+	}
+
+	if len(bounds) > 0 {
+		filter["_id"] = bounds
+	}
+}
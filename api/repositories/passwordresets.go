@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+	types "auth_blog_service/types"
+)
+
+func InsertPasswordReset(connection *mongo.Database, reset models.PasswordReset) error {
+	_, err := connection.Collection("password_resets").InsertOne(context.TODO(), reset)
+
+	return err
+}
+
+// StartPasswordReset stores a new reset token for the user and invalidates
+// any reset that was still pending, so only the latest link works.
+func StartPasswordReset(connection *mongo.Database, userId primitive.ObjectID, hash string, duration time.Duration) (models.PasswordReset, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	_, err := connection.Collection("password_resets").UpdateMany(context.TODO(), bson.M{"_userId": userId, "used": false}, update)
+
+	if err != nil {
+		return models.PasswordReset{}, err
+	}
+
+	var reset models.PasswordReset
+
+	reset.UserID = userId
+	reset.Hash = hash
+	reset.CreatedDate.Time = time.Now()
+	reset.ExpiresDate = reset.CreatedDate.Time.Add(duration)
+
+	err = InsertPasswordReset(connection, reset)
+
+	if err != nil {
+		return models.PasswordReset{}, err
+	}
+
+	return reset, err
+}
+
+func UsePasswordReset(connection *mongo.Database, hash string) (models.PasswordReset, error) {
+	var reset models.PasswordReset
+
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	err := connection.Collection("password_resets").FindOneAndUpdate(
+		context.TODO(),
+		bson.M{"hash": hash, "used": false},
+		update,
+	).Decode(&reset)
+
+	if err != nil {
+		return models.PasswordReset{}, fmt.Errorf("Invalid or expired reset token")
+	}
+
+	if time.Now().After(reset.ExpiresDate) {
+		return models.PasswordReset{}, fmt.Errorf("Invalid or expired reset token")
+	}
+
+	return reset, err
+}
+
+func UpdateUserPassword(connection *mongo.Database, userId primitive.ObjectID, password types.Password) error {
+	update := bson.M{
+		"$set": bson.M{
+			"password": password,
+		},
+	}
+
+	result, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": userId}, update)
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("User doesn't exist")
+	}
+
+	return err
+}
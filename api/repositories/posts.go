@@ -9,11 +9,14 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gopkg.in/mgo.v2/bson"
 
 	constants "auth_blog_service/constants"
 	"auth_blog_service/models"
+	search "auth_blog_service/search"
 	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
 )
 
 func QueryPosts(connection *mongo.Database, filter bson.M) ([]models.Post, error, int) {
@@ -73,19 +76,82 @@ func GetPosts(connection *mongo.Database) ([]serializers.Post, error, int) {
 	return serializers.SerializeManyPosts(posts), err, status
 }
 
-func CreatePost(connection *mongo.Database, body io.Reader) (serializers.Post, error, int) {
+var postSortFields = map[string]string{"createdDate": "createdDate.time", "title": "title"}
+
+// ListPosts returns one page of the posts matching the query, among those
+// the visibility filter lets through.
+func ListPosts(connection *mongo.Database, visibility bson.M, query types.ListQuery) ([]serializers.Post, types.Page, error, int) {
+	return listPosts(connection, visibility, query)
+}
+
+func listPosts(connection *mongo.Database, visibility bson.M, query types.ListQuery) ([]serializers.Post, types.Page, error, int) {
+	filter := bson.M{}
+
+	for key, value := range visibility {
+		filter[key] = value
+	}
+
+	if !query.Author.IsZero() {
+		filter["_userId"] = query.Author
+	}
+
+	if query.Title != "" {
+		filter["title"] = prefixFilter(query.Title)
+	}
+
+	createdFilter(filter, "createdDate.time", query)
+
+	posts := []models.Post{}
+
+	page, err, status := findPage(connection, "posts", filter, query, postSortFields, func(cur *mongo.Cursor) error {
+		var post models.Post
+
+		if err := cur.Decode(&post); err != nil {
+			return err
+		}
+
+		posts = append(posts, post)
+
+		return nil
+	})
+
+	if err != nil {
+		return []serializers.Post{}, types.Page{}, err, status
+	}
+
+	return serializers.SerializeManyPosts(posts), page, nil, constants.Success
+}
+
+// CreatePost stores a new post as a draft. A non-zero userId overrides the
+// author given in the body.
+func CreatePost(connection *mongo.Database, body io.Reader, userId primitive.ObjectID) (serializers.Post, error, int) {
 	var post models.Post
 
 	_ = json.NewDecoder(body).Decode(&post)
 
 	post.CreatedDate.Time = time.Now()
+	post.Status = constants.PostDraft
+	post.PublishedDate = time.Time{}
+	post.PublishAt = time.Time{}
+
+	if !userId.IsZero() {
+		post.UserID = userId
+	}
 
-	_, err, _ := GetUser(connection, post.UserID.String())
+	_, err, _ := GetUser(connection, post.UserID.Hex())
 
 	if err != nil {
 		return serializers.Post{}, fmt.Errorf("Post User doesn't exists, or is empty"), constants.NotFound
 	}
 
+	if !post.GroupID.IsZero() {
+		_, err, _ := QueryGroup(connection, bson.M{"_id": post.GroupID})
+
+		if err != nil {
+			return serializers.Post{}, fmt.Errorf("Post Group doesn't exist"), constants.UnprocessableEntity
+		}
+	}
+
 	if post.Body == "" {
 		return serializers.Post{}, fmt.Errorf("Post body is required"), constants.UnprocessableEntity
 	}
@@ -94,15 +160,26 @@ func CreatePost(connection *mongo.Database, body io.Reader) (serializers.Post, e
 		return serializers.Post{}, fmt.Errorf("Post title is required"), constants.UnprocessableEntity
 	}
 
+	if len(post.Title)+len(post.Body) > constants.MaxPostSize {
+		return serializers.Post{}, fmt.Errorf("Post is too large"), constants.RequestEntityTooLarge
+	}
+
+	post.ID = primitive.NewObjectID()
+	post.Revision = 1
+
 	err = InsertPost(connection, post)
 
 	if err != nil {
 		return serializers.Post{}, err, constants.BadRequest
 	}
 
-	posts, _, _ := QueryPosts(connection, bson.M{"title": post.Title, "body": post.Body})
+	err = InsertPostRevision(connection, firstRevision(post))
 
-	return serializers.SerializeOnePost(posts[0]), err, constants.Success
+	if err != nil {
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
+	return serializers.SerializeOnePost(post), err, constants.Success
 }
 
 func GetPost(connection *mongo.Database, idParam string) (serializers.Post, error, int) {
@@ -117,27 +194,60 @@ func GetPost(connection *mongo.Database, idParam string) (serializers.Post, erro
 	return serializers.SerializeOnePost(post), err, status
 }
 
-func UpdatePost(connection *mongo.Database, idParam string, body io.Reader) (serializers.Post, error, int) {
+// UpdatePost applies the body to the post and records the result as a new
+// revision by editorId. Without canPublish, changing the content of a
+// published post sends it back to review.
+func UpdatePost(connection *mongo.Database, idParam string, body io.Reader, canReassign bool, canPublish bool, editorId primitive.ObjectID) (serializers.Post, error, int) {
 	var post models.Post
 
 	id, _ := primitive.ObjectIDFromHex(idParam)
 
 	_ = json.NewDecoder(body).Decode(&post)
 
-	_, err, _ := QueryPost(connection, bson.M{"_id": id})
+	current, err, _ := QueryPost(connection, bson.M{"_id": id})
 
 	if err != nil {
 		return serializers.Post{}, fmt.Errorf("Requested Post doesn't exist"), constants.NotFound
 	}
 
+	if !canReassign && !post.UserID.IsZero() && post.UserID != current.UserID {
+		return serializers.Post{}, fmt.Errorf("Not allowed to change the Post User"), constants.Forbidden
+	}
+
+	if !post.GroupID.IsZero() && post.GroupID != current.GroupID {
+		if !canReassign {
+			return serializers.Post{}, fmt.Errorf("Not allowed to change the Post Group"), constants.Forbidden
+		}
+
+		_, err, _ := QueryGroup(connection, bson.M{"_id": post.GroupID})
+
+		if err != nil {
+			return serializers.Post{}, fmt.Errorf("Post Group doesn't exist"), constants.UnprocessableEntity
+		}
+	}
+
 	if post.UserID.Hex() != "000000000000000000000000" {
-		_, err, _ := GetUser(connection, post.UserID.String())
+		_, err, _ := GetUser(connection, post.UserID.Hex())
 
 		if err != nil {
 			return serializers.Post{}, fmt.Errorf("Valid Post User is required"), constants.UnprocessableEntity
 		}
 	}
 
+	title, text := current.Title, current.Body
+
+	if post.Title != "" {
+		title = post.Title
+	}
+
+	if post.Body != "" {
+		text = post.Body
+	}
+
+	if len(title)+len(text) > constants.MaxPostSize {
+		return serializers.Post{}, fmt.Errorf("Post is too large"), constants.RequestEntityTooLarge
+	}
+
 	setObj := bson.M{}
 
 	if post.Body != "" {
@@ -152,14 +262,82 @@ func UpdatePost(connection *mongo.Database, idParam string, body io.Reader) (ser
 		setObj["_userId"] = post.UserID
 	}
 
+	if !post.GroupID.IsZero() {
+		setObj["_groupId"] = post.GroupID
+	}
+
+	return updatePostContent(connection, current, setObj, canPublish, editorId, 0)
+}
+
+// TransitionPost moves a post along its lifecycle. The status is checked in
+// the update itself, so concurrent transitions can't both apply.
+func TransitionPost(connection *mongo.Database, id primitive.ObjectID, transition types.PostTransition) (serializers.Post, error, int) {
 	update := bson.M{
-		"$set": setObj,
+		"$set": bson.M{"status": transition.To},
+	}
+
+	if transition.To == constants.PostPublished {
+		update = publishUpdate(time.Now())
+	}
+
+	result, err := connection.Collection("posts").UpdateOne(context.TODO(), bson.M{
+		"_id":    id,
+		"status": bson.M{"$in": transition.From},
+	}, update)
+
+	if err != nil {
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
+	if result.MatchedCount == 0 {
+		current, err, _ := QueryPost(connection, bson.M{"_id": id})
+
+		if err != nil {
+			return serializers.Post{}, fmt.Errorf("Requested Post doesn't exist"), constants.NotFound
+		}
+
+		return serializers.Post{}, fmt.Errorf("Post can't become %s from %s", transition.To, current.Status), constants.Conflict
 	}
 
-	_, err = connection.Collection("posts").UpdateOne(context.TODO(), bson.M{"_id": id}, update)
+	post, err, status := QueryPost(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Post{}, err, status
+	}
+
+	return serializers.SerializeOnePost(post), nil, constants.Success
+}
+
+// SchedulePost sets when the scheduler publishes the post. Only posts that
+// could be published now can be scheduled.
+func SchedulePost(connection *mongo.Database, id primitive.ObjectID, publishAt time.Time) (serializers.Post, error, int) {
+	if !publishAt.After(time.Now()) {
+		return serializers.Post{}, fmt.Errorf("Post publishAt must be in the future"), constants.UnprocessableEntity
+	}
+
+	return schedulePost(connection, id, bson.M{"$set": bson.M{"publishAt": publishAt}})
+}
+
+func UnschedulePost(connection *mongo.Database, id primitive.ObjectID) (serializers.Post, error, int) {
+	return schedulePost(connection, id, bson.M{"$unset": bson.M{"publishAt": ""}})
+}
+
+func schedulePost(connection *mongo.Database, id primitive.ObjectID, update bson.M) (serializers.Post, error, int) {
+	result, err := connection.Collection("posts").UpdateOne(context.TODO(), bson.M{
+		"_id":    id,
+		"status": bson.M{"$in": constants.PostTransitions["publish"].From},
+	}, update)
 
 	if err != nil {
-		return serializers.Post{}, err, constants.UnprocessableEntity
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
+	if result.MatchedCount == 0 {
+		if _, err, _ := QueryPost(connection, bson.M{"_id": id}); err != nil {
+			return serializers.Post{}, fmt.Errorf("Requested Post doesn't exist"), constants.NotFound
+		}
+
+		return serializers.Post{}, fmt.Errorf("Post is already published"), constants.Conflict
 	}
 
 	post, err, status := QueryPost(connection, bson.M{"_id": id})
@@ -168,7 +346,43 @@ func UpdatePost(connection *mongo.Database, idParam string, body io.Reader) (ser
 		return serializers.Post{}, err, status
 	}
 
-	return serializers.SerializeOnePost(post), err, status
+	return serializers.SerializeOnePost(post), nil, constants.Success
+}
+
+// PublishDuePosts publishes every post whose publishAt has passed. Each post
+// is claimed by an atomic update, so no post is published twice.
+func PublishDuePosts(connection *mongo.Database, now time.Time) ([]serializers.Post, error) {
+	published := []serializers.Post{}
+
+	filter := bson.M{
+		"status":    bson.M{"$in": constants.PostTransitions["publish"].From},
+		"publishAt": bson.M{"$lte": now},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	for {
+		var post models.Post
+
+		err := connection.Collection("posts").FindOneAndUpdate(context.TODO(), filter, publishUpdate(now), opts).Decode(&post)
+
+		if err == mongo.ErrNoDocuments {
+			return published, nil
+		}
+
+		if err != nil {
+			return published, err
+		}
+
+		published = append(published, serializers.SerializeOnePost(post))
+	}
+}
+
+func publishUpdate(now time.Time) bson.M {
+	return bson.M{
+		"$set":   bson.M{"status": constants.PostPublished, "publishedDate": now},
+		"$unset": bson.M{"publishAt": ""},
+	}
 }
 
 func DeletePost(connection *mongo.Database, idParam string) (serializers.Post, error, int) {
@@ -184,5 +398,56 @@ func DeletePost(connection *mongo.Database, idParam string) (serializers.Post, e
 		return serializers.Post{}, fmt.Errorf("Requested Post doesn't exist"), constants.NotFound
 	}
 
+	_, err = connection.Collection("post_revisions").DeleteMany(context.TODO(), bson.M{"_postId": id})
+
+	if err != nil {
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
 	return serializers.Post{}, err, constants.Success
 }
+
+// SearchPosts runs the query on the search backend and loads the posts hit,
+// in order of relevance. The backend applies filter before limiting; hits
+// whose post is gone or no longer visible are still skipped, since an
+// in-memory index can lag behind the collection.
+func SearchPosts(connection *mongo.Database, backend search.Backend, visibility bson.M, filter *search.Filter, query search.Query, limit int) ([]serializers.PostHit, error, int) {
+	if query.Empty() {
+		return []serializers.PostHit{}, fmt.Errorf("Search query is required"), constants.UnprocessableEntity
+	}
+
+	hits, err := backend.Search(query, filter, limit)
+
+	if err != nil {
+		return []serializers.PostHit{}, err, constants.InternalServerError
+	}
+
+	ids := []primitive.ObjectID{}
+
+	for _, hit := range hits {
+		id, _ := primitive.ObjectIDFromHex(hit.ID)
+		ids = append(ids, id)
+	}
+
+	posts, err, status := QueryPosts(connection, bson.M{"$and": []bson.M{{"_id": bson.M{"$in": ids}}, visibility}})
+
+	if err != nil {
+		return []serializers.PostHit{}, err, status
+	}
+
+	byId := map[string]models.Post{}
+
+	for _, post := range posts {
+		byId[post.ID.Hex()] = post
+	}
+
+	results := []serializers.PostHit{}
+
+	for _, hit := range hits {
+		if post, ok := byId[hit.ID]; ok {
+			results = append(results, serializers.SerializeOnePostHit(post, hit.Score, hit.Title, hit.Snippet))
+		}
+	}
+
+	return results, nil, constants.Success
+}
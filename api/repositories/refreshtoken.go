@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gopkg.in/mgo.v2/bson"
+
+	"auth_blog_service/models"
+)
+
+func QueryRefreshToken(connection *mongo.Database, filter bson.M) (models.RefreshToken, error) {
+	var refreshToken models.RefreshToken
+
+	err := connection.Collection("refresh_tokens").FindOne(context.TODO(), filter).Decode(&refreshToken)
+
+	if err != nil {
+		return models.RefreshToken{}, fmt.Errorf("Refresh token doesn't exist")
+	}
+
+	return refreshToken, err
+}
+
+func InsertRefreshToken(connection *mongo.Database, refreshToken models.RefreshToken) error {
+	_, err := connection.Collection("refresh_tokens").InsertOne(context.TODO(), refreshToken)
+
+	return err
+}
+
+func StartRefreshToken(connection *mongo.Database, refreshToken models.RefreshToken, duration time.Duration) (models.RefreshToken, error) {
+	refreshToken.CreatedDate.Time = time.Now()
+	refreshToken.ExpiresDate = refreshToken.CreatedDate.Time.Add(duration)
+	refreshToken.Active = true
+
+	err := InsertRefreshToken(connection, refreshToken)
+
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	return refreshToken, err
+}
+
+func GetRefreshToken(connection *mongo.Database, hash string) (models.RefreshToken, error) {
+	refreshToken, err := QueryRefreshToken(connection, bson.M{"hash": hash})
+
+	if err != nil {
+		return models.RefreshToken{}, fmt.Errorf("Refresh token doesn't exist")
+	}
+
+	return refreshToken, err
+}
+
+// UseRefreshToken flags the token as consumed. The filter only matches an
+// unused, active token, so two concurrent refreshes can't both succeed.
+func UseRefreshToken(connection *mongo.Database, hash string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"used": true,
+		},
+	}
+
+	result, err := connection.Collection("refresh_tokens").UpdateOne(
+		context.TODO(),
+		bson.M{"hash": hash, "used": false, "active": true},
+		update,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if result.ModifiedCount == 0 {
+		return fmt.Errorf("Refresh token already used")
+	}
+
+	return err
+}
+
+func RevokeTokenFamily(connection *mongo.Database, family string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"active": false,
+		},
+	}
+
+	_, err := connection.Collection("refresh_tokens").UpdateMany(context.TODO(), bson.M{"family": family}, update)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("sessions").UpdateMany(context.TODO(), bson.M{"family": family}, update)
+
+	return err
+}
@@ -0,0 +1,222 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+
+	constants "auth_blog_service/constants"
+	diff "auth_blog_service/diff"
+	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
+)
+
+func QueryPostRevisions(connection *mongo.Database, filter bson.M) ([]models.PostRevision, error, int) {
+	var revisions []models.PostRevision = []models.PostRevision{}
+
+	opts := options.Find().SetSort(bson.M{"number": 1})
+
+	cur, err := connection.Collection("post_revisions").Find(context.TODO(), filter, opts)
+
+	if err != nil {
+		return []models.PostRevision{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var revision models.PostRevision
+		err := cur.Decode(&revision)
+
+		if err != nil {
+			return []models.PostRevision{}, err, constants.InternalServerError
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.PostRevision{}, err, constants.InternalServerError
+	}
+
+	return revisions, err, constants.Success
+}
+
+func QueryPostRevision(connection *mongo.Database, filter bson.M) (models.PostRevision, error, int) {
+	var revision models.PostRevision
+
+	err := connection.Collection("post_revisions").FindOne(context.TODO(), filter).Decode(&revision)
+
+	if err != nil {
+		return models.PostRevision{}, fmt.Errorf("Requested Revision doesn't exist"), constants.NotFound
+	}
+
+	return revision, err, constants.Success
+}
+
+func InsertPostRevision(connection *mongo.Database, revision models.PostRevision) error {
+	_, err := connection.Collection("post_revisions").InsertOne(context.TODO(), revision)
+
+	return err
+}
+
+func GetPostRevisions(connection *mongo.Database, postId primitive.ObjectID) ([]serializers.PostRevision, error, int) {
+	revisions, err, status := QueryPostRevisions(connection, bson.M{"_postId": postId})
+
+	if err != nil {
+		return []serializers.PostRevision{}, err, status
+	}
+
+	return serializers.SerializeManyPostRevisions(revisions), nil, constants.Success
+}
+
+// GetPostRevision finds a revision by its number, as given in a URL.
+func GetPostRevision(connection *mongo.Database, postId primitive.ObjectID, numberParam string) (models.PostRevision, error, int) {
+	number, err := strconv.Atoi(numberParam)
+
+	if err != nil {
+		return models.PostRevision{}, fmt.Errorf("Requested Revision doesn't exist"), constants.NotFound
+	}
+
+	return QueryPostRevision(connection, bson.M{"_postId": postId, "number": number})
+}
+
+// DiffPostRevisions renders the changes between two revisions as a unified
+// diff of the title followed by the body.
+func DiffPostRevisions(connection *mongo.Database, postId primitive.ObjectID, fromParam string, toParam string) (serializers.PostRevisionDiff, error, int) {
+	from, err, status := GetPostRevision(connection, postId, fromParam)
+
+	if err != nil {
+		return serializers.PostRevisionDiff{}, err, status
+	}
+
+	to, err, status := GetPostRevision(connection, postId, toParam)
+
+	if err != nil {
+		return serializers.PostRevisionDiff{}, err, status
+	}
+
+	// Revisions from before MaxPostSize may be larger than any post can be now.
+	if len(from.Title)+len(from.Body) > constants.MaxPostSize || len(to.Title)+len(to.Body) > constants.MaxPostSize {
+		return serializers.PostRevisionDiff{}, fmt.Errorf("Revisions are too large to diff"), constants.RequestEntityTooLarge
+	}
+
+	return serializers.PostRevisionDiff{
+		From: from.Number,
+		To:   to.Number,
+		Diff: diff.Unified(
+			"revision/"+strconv.Itoa(from.Number),
+			"revision/"+strconv.Itoa(to.Number),
+			from.Title+"\n\n"+from.Body,
+			to.Title+"\n\n"+to.Body,
+		),
+	}, nil, constants.Success
+}
+
+// RestorePostRevision makes the content of an older revision current again,
+// as a new revision.
+func RestorePostRevision(connection *mongo.Database, post models.Post, numberParam string, canPublish bool, editorId primitive.ObjectID) (serializers.Post, error, int) {
+	revision, err, status := GetPostRevision(connection, post.ID, numberParam)
+
+	if err != nil {
+		return serializers.Post{}, err, status
+	}
+
+	setObj := bson.M{
+		"title": revision.Title,
+		"body":  revision.Body,
+	}
+
+	return updatePostContent(connection, post, setObj, canPublish, editorId, revision.Number)
+}
+
+// updatePostContent applies setObj to the post and stores the outcome as the
+// next revision. The revision number comes from the same atomic update, so
+// concurrent edits can't share a number. Content changes to a published post
+// go back to review unless the editor may publish.
+func updatePostContent(connection *mongo.Database, current models.Post, setObj bson.M, canPublish bool, editorId primitive.ObjectID, restoredFrom int) (serializers.Post, error, int) {
+	if err := ensureFirstRevision(connection, current); err != nil {
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
+	_, title := setObj["title"]
+	_, body := setObj["body"]
+
+	if current.Status == constants.PostPublished && !canPublish && (title || body) {
+		setObj["status"] = constants.PostInReview
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"revision": 1},
+	}
+
+	if len(setObj) > 0 {
+		update["$set"] = setObj
+	}
+
+	var post models.Post
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err := connection.Collection("posts").FindOneAndUpdate(context.TODO(), bson.M{"_id": current.ID}, update, opts).Decode(&post)
+
+	if err != nil {
+		return serializers.Post{}, err, constants.UnprocessableEntity
+	}
+
+	err = InsertPostRevision(connection, models.PostRevision{
+		PostID:       post.ID,
+		Number:       post.Revision,
+		UserID:       editorId,
+		Title:        post.Title,
+		Body:         post.Body,
+		RestoredFrom: restoredFrom,
+		CreatedDate:  time.Now(),
+	})
+
+	if err != nil {
+		return serializers.Post{}, err, constants.InternalServerError
+	}
+
+	return serializers.SerializeOnePost(post), nil, constants.Success
+}
+
+// ensureFirstRevision records the content of posts written before revisions
+// existed, so their first edit can still be undone.
+func ensureFirstRevision(connection *mongo.Database, post models.Post) error {
+	if post.Revision > 0 {
+		return nil
+	}
+
+	err := InsertPostRevision(connection, firstRevision(post))
+
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	_, err = connection.Collection("posts").UpdateOne(context.TODO(), bson.M{
+		"_id":      post.ID,
+		"revision": bson.M{"$in": []interface{}{0, nil}},
+	}, bson.M{
+		"$set": bson.M{"revision": 1},
+	})
+
+	return err
+}
+
+func firstRevision(post models.Post) models.PostRevision {
+	return models.PostRevision{
+		PostID:      post.ID,
+		Number:      1,
+		UserID:      post.UserID,
+		Title:       post.Title,
+		Body:        post.Body,
+		CreatedDate: post.CreatedDate.Time,
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gopkg.in/mgo.v2/bson"
 
 	constants "auth_blog_service/constants"
 	"auth_blog_service/models"
 	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
 )
 
 func QueryRoles(connection *mongo.Database, filter bson.M) ([]models.Role, error, int) {
@@ -72,6 +75,39 @@ func GetRoles(connection *mongo.Database) ([]serializers.Role, error, int) {
 	return serializers.SerializeManyRoles(roles), err, status
 }
 
+var roleSortFields = map[string]string{"createdDate": "_id", "name": "name"}
+
+// ListRoles returns one page of the roles matching the query.
+func ListRoles(connection *mongo.Database, query types.ListQuery) ([]serializers.Role, types.Page, error, int) {
+	filter := bson.M{}
+
+	if query.Name != "" {
+		filter["name"] = prefixFilter(query.Name)
+	}
+
+	createdIDFilter(filter, query)
+
+	roles := []models.Role{}
+
+	page, err, status := findPage(connection, "roles", filter, query, roleSortFields, func(cur *mongo.Cursor) error {
+		var role models.Role
+
+		if err := cur.Decode(&role); err != nil {
+			return err
+		}
+
+		roles = append(roles, role)
+
+		return nil
+	})
+
+	if err != nil {
+		return []serializers.Role{}, types.Page{}, err, status
+	}
+
+	return serializers.SerializeManyRoles(roles), page, nil, constants.Success
+}
+
 func CreateRole(connection *mongo.Database, body io.Reader) (serializers.Role, error, int) {
 	var role models.Role
 
@@ -91,6 +127,14 @@ func CreateRole(connection *mongo.Database, body io.Reader) (serializers.Role, e
 		return serializers.Role{}, fmt.Errorf("Role permissions is required"), constants.UnprocessableEntity
 	}
 
+	if err := constants.ValidatePermissions(role.Permissions); err != nil {
+		return serializers.Role{}, err, constants.UnprocessableEntity
+	}
+
+	if err := validateRoleParents(connection, primitive.NilObjectID, role.ParentIDs); err != nil {
+		return serializers.Role{}, err, constants.UnprocessableEntity
+	}
+
 	err := InsertRole(connection, role)
 
 	if err != nil {
@@ -116,10 +160,14 @@ func GetRole(connection *mongo.Database, idParam string) (serializers.Role, erro
 
 func UpdateRole(connection *mongo.Database, idParam string, body io.Reader) (serializers.Role, error, int) {
 	var role models.Role
+	var fields map[string]interface{}
 
 	id, _ := primitive.ObjectIDFromHex(idParam)
 
-	_ = json.NewDecoder(body).Decode(&role)
+	raw, _ := ioutil.ReadAll(body)
+
+	_ = json.Unmarshal(raw, &role)
+	_ = json.Unmarshal(raw, &fields)
 
 	aux1, err, _ := QueryRoles(connection, bson.M{"_id": id})
 
@@ -133,6 +181,14 @@ func UpdateRole(connection *mongo.Database, idParam string, body io.Reader) (ser
 		return serializers.Role{}, fmt.Errorf("A Role with this name already exists"), constants.UnprocessableEntity
 	}
 
+	if err := constants.ValidatePermissions(role.Permissions); err != nil {
+		return serializers.Role{}, err, constants.UnprocessableEntity
+	}
+
+	if err := validateRoleParents(connection, id, role.ParentIDs); err != nil {
+		return serializers.Role{}, err, constants.UnprocessableEntity
+	}
+
 	setObj := bson.M{}
 
 	if role.Name != "" {
@@ -143,6 +199,14 @@ func UpdateRole(connection *mongo.Database, idParam string, body io.Reader) (ser
 		setObj["permissions"] = role.Permissions
 	}
 
+	if _, ok := fields["requireMfa"]; ok {
+		setObj["requireMfa"] = role.RequireMFA
+	}
+
+	if role.ParentIDs != nil {
+		setObj["_parentIds"] = role.ParentIDs
+	}
+
 	update := bson.M{
 		"$set": setObj,
 	}
@@ -162,9 +226,51 @@ func UpdateRole(connection *mongo.Database, idParam string, body io.Reader) (ser
 	return serializers.SerializeOneRole(role), err, status
 }
 
-func DeleteRole(connection *mongo.Database, idParam string) (serializers.Role, error, int) {
+// DeleteRole refuses to delete a role users still hold, unless reassignTo
+// names the role they should move to. Roles inheriting from it lose it as a
+// parent.
+func DeleteRole(connection *mongo.Database, idParam string, reassignTo string) (serializers.Role, error, int) {
 	id, _ := primitive.ObjectIDFromHex(idParam)
 
+	_, err, _ := QueryRole(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Role{}, fmt.Errorf("Requested Role doesn't exist"), constants.NotFound
+	}
+
+	users, _, _ := QueryUsers(connection, bson.M{"$or": []bson.M{{"_roleId": id}, {"_roleIds": id}}})
+	groups, _, _ := QueryGroups(connection, bson.M{"members._roleId": id})
+
+	if len(users) > 0 || len(groups) > 0 {
+		if reassignTo == "" {
+			return serializers.Role{}, fmt.Errorf("Role is still assigned to %d users and %d groups, reassign them with reassignTo", len(users), len(groups)), constants.Conflict
+		}
+
+		reassignId, _ := primitive.ObjectIDFromHex(reassignTo)
+
+		_, err, _ := QueryRole(connection, bson.M{"_id": reassignId})
+
+		if err != nil || reassignId == id {
+			return serializers.Role{}, fmt.Errorf("Valid reassignTo Role is required"), constants.UnprocessableEntity
+		}
+
+		err = ReassignRole(connection, id, reassignId)
+
+		if err != nil {
+			return serializers.Role{}, err, constants.InternalServerError
+		}
+	}
+
+	_, err = connection.Collection("roles").UpdateMany(context.TODO(), bson.M{"_parentIds": id}, bson.M{
+		"$pull": bson.M{
+			"_parentIds": id,
+		},
+	})
+
+	if err != nil {
+		return serializers.Role{}, err, constants.InternalServerError
+	}
+
 	result, err := connection.Collection("roles").DeleteOne(context.TODO(), bson.M{"_id": id})
 
 	if err != nil {
@@ -177,3 +283,121 @@ func DeleteRole(connection *mongo.Database, idParam string) (serializers.Role, e
 
 	return serializers.Role{}, err, constants.Success
 }
+
+// ResolveRoles loads the given roles and every role they inherit from, in
+// breadth-first order so the given roles come first. Each role appears once,
+// which also keeps an inheritance cycle from looping.
+func ResolveRoles(connection *mongo.Database, roleIds []primitive.ObjectID) ([]models.Role, error) {
+	roles := []models.Role{}
+	visited := map[primitive.ObjectID]bool{}
+	queue := append([]primitive.ObjectID{}, roleIds...)
+
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		if visited[id] {
+			continue
+		}
+
+		visited[id] = true
+
+		role, err, _ := QueryRole(connection, bson.M{"_id": id})
+
+		if err != nil {
+			return []models.Role{}, err
+		}
+
+		roles = append(roles, role)
+		queue = append(queue, role.ParentIDs...)
+	}
+
+	return roles, nil
+}
+
+// EffectivePermissions merges the permissions of resolved roles.
+func EffectivePermissions(roles []models.Role) []string {
+	permissions := []string{}
+	seen := map[string]bool{}
+
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	return permissions
+}
+
+// validateRoleParents makes sure every parent exists and that none of them
+// inherits, directly or not, from the role itself.
+func validateRoleParents(connection *mongo.Database, roleId primitive.ObjectID, parentIds []primitive.ObjectID) error {
+	for _, parentId := range parentIds {
+		if parentId == roleId {
+			return fmt.Errorf("A Role can't inherit from itself")
+		}
+	}
+
+	ancestors, err := ResolveRoles(connection, parentIds)
+
+	if err != nil {
+		return fmt.Errorf("Parent Role doesn't exist")
+	}
+
+	for _, ancestor := range ancestors {
+		if !roleId.IsZero() && ancestor.ID == roleId {
+			return fmt.Errorf("Role inheritance can't contain a cycle")
+		}
+	}
+
+	return nil
+}
+
+// ReassignRole moves every user and group member holding roleId to
+// reassignId.
+func ReassignRole(connection *mongo.Database, roleId primitive.ObjectID, reassignId primitive.ObjectID) error {
+	_, err := connection.Collection("users").UpdateMany(context.TODO(), bson.M{"_roleIds": roleId}, bson.M{
+		"$addToSet": bson.M{
+			"_roleIds": reassignId,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("users").UpdateMany(context.TODO(), bson.M{"_roleIds": roleId}, bson.M{
+		"$pull": bson.M{
+			"_roleIds": roleId,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("users").UpdateMany(context.TODO(), bson.M{"_roleId": roleId}, bson.M{
+		"$set": bson.M{
+			"_roleId": reassignId,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	arrayFilters := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"member._roleId": roleId}},
+	})
+
+	_, err = connection.Collection("groups").UpdateMany(context.TODO(), bson.M{"members._roleId": roleId}, bson.M{
+		"$set": bson.M{
+			"members.$[member]._roleId": reassignId,
+		},
+	}, arrayFilters)
+
+	return err
+}
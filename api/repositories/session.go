@@ -5,12 +5,44 @@ import (
 	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gopkg.in/mgo.v2/bson"
 
+	constants "auth_blog_service/constants"
 	"auth_blog_service/models"
+	serializers "auth_blog_service/serializers"
 )
 
+func QuerySessions(connection *mongo.Database, filter bson.M) ([]models.Session, error, int) {
+	var sessions []models.Session = []models.Session{}
+
+	cur, err := connection.Collection("sessions").Find(context.TODO(), filter)
+
+	if err != nil {
+		return []models.Session{}, err, constants.InternalServerError
+	}
+
+	defer cur.Close(context.TODO())
+
+	for cur.Next(context.TODO()) {
+		var session models.Session
+		err := cur.Decode(&session)
+
+		if err != nil {
+			return []models.Session{}, err, constants.InternalServerError
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	if err := cur.Err(); err != nil {
+		return []models.Session{}, err, constants.InternalServerError
+	}
+
+	return sessions, err, constants.Success
+}
+
 func QuerySession(connection *mongo.Database, filter bson.M) (models.Session, error) {
 	var session models.Session
 
@@ -29,11 +61,10 @@ func InsertSession(connection *mongo.Database, session models.Session) error {
 	return err
 }
 
-func StartSession(connection *mongo.Database, token string) (models.Session, error) {
-	var session models.Session
-
-	session.Token = token
+func StartSession(connection *mongo.Database, session models.Session, duration time.Duration) (models.Session, error) {
 	session.CreatedDate.Time = time.Now()
+	session.LastSeenDate.Time = session.CreatedDate.Time
+	session.ExpiresDate = session.CreatedDate.Time.Add(duration)
 	session.Active = true
 
 	err := InsertSession(connection, session)
@@ -45,6 +76,18 @@ func StartSession(connection *mongo.Database, token string) (models.Session, err
 	return session, err
 }
 
+func TouchSession(connection *mongo.Database, token string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"lastSeenDate.time": time.Now(),
+		},
+	}
+
+	_, err := connection.Collection("sessions").UpdateOne(context.TODO(), bson.M{"token": token}, update)
+
+	return err
+}
+
 func StopSession(connection *mongo.Database, token string) error {
 	update := bson.M{
 		"$set": bson.M{
@@ -54,6 +97,50 @@ func StopSession(connection *mongo.Database, token string) error {
 
 	_, err := connection.Collection("sessions").UpdateOne(context.TODO(), bson.M{"token": token}, update)
 
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("refresh_tokens").UpdateMany(context.TODO(), bson.M{"sessionToken": token}, update)
+
+	return err
+}
+
+func StopUserSessions(connection *mongo.Database, userId primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"active": false,
+		},
+	}
+
+	_, err := connection.Collection("sessions").UpdateMany(context.TODO(), bson.M{"_userId": userId}, update)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("refresh_tokens").UpdateMany(context.TODO(), bson.M{"_userId": userId}, update)
+
+	return err
+}
+
+// StopOtherUserSessions signs the user out everywhere except on the session
+// identified by token.
+func StopOtherUserSessions(connection *mongo.Database, userId primitive.ObjectID, token string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"active": false,
+		},
+	}
+
+	_, err := connection.Collection("sessions").UpdateMany(context.TODO(), bson.M{"_userId": userId, "token": bson.M{"$ne": token}}, update)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = connection.Collection("refresh_tokens").UpdateMany(context.TODO(), bson.M{"_userId": userId, "sessionToken": bson.M{"$ne": token}}, update)
+
 	return err
 }
 
@@ -66,3 +153,53 @@ func GetSession(connection *mongo.Database, token string) (models.Session, error
 
 	return session, err
 }
+
+func GetUserSessions(connection *mongo.Database, userId primitive.ObjectID) ([]serializers.Session, error, int) {
+	sessions, err, status := QuerySessions(connection, bson.M{
+		"_userId":     userId,
+		"active":      true,
+		"expiresDate": bson.M{"$gt": time.Now()},
+	})
+
+	if err != nil {
+		return []serializers.Session{}, err, status
+	}
+
+	return serializers.SerializeManySessions(sessions), err, status
+}
+
+func DeleteUserSession(connection *mongo.Database, userId primitive.ObjectID, idParam string) (serializers.Session, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	session, err := QuerySession(connection, bson.M{"_id": id, "_userId": userId})
+
+	if err != nil {
+		return serializers.Session{}, fmt.Errorf("Requested Session doesn't exist"), constants.NotFound
+	}
+
+	err = StopSession(connection, session.Token)
+
+	if err != nil {
+		return serializers.Session{}, err, constants.InternalServerError
+	}
+
+	return serializers.Session{}, err, constants.Success
+}
+
+func DeleteUserSessions(connection *mongo.Database, idParam string) (serializers.Session, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	user, err, status := QueryUser(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return serializers.Session{}, err, status
+	}
+
+	err = StopUserSessions(connection, user.ID)
+
+	if err != nil {
+		return serializers.Session{}, err, constants.InternalServerError
+	}
+
+	return serializers.Session{}, err, constants.Success
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/mail"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,6 +15,7 @@ import (
 	constants "auth_blog_service/constants"
 	"auth_blog_service/models"
 	serializers "auth_blog_service/serializers"
+	types "auth_blog_service/types"
 )
 
 func QueryUsers(connection *mongo.Database, filter bson.M) ([]models.User, error, int) {
@@ -73,16 +75,108 @@ func GetUsers(connection *mongo.Database) ([]serializers.User, error, int) {
 	return serializers.SerializeManyUsers(users), err, status
 }
 
+var userSortFields = map[string]string{"createdDate": "_id", "name": "name", "username": "username"}
+
+// ListUsers returns one page of the users matching the query.
+func ListUsers(connection *mongo.Database, query types.ListQuery) ([]serializers.User, types.Page, error, int) {
+	filter := bson.M{}
+
+	if query.UserName != "" {
+		filter["username"] = prefixFilter(query.UserName)
+	}
+
+	if query.Name != "" {
+		filter["name"] = prefixFilter(query.Name)
+	}
+
+	createdIDFilter(filter, query)
+
+	users := []models.User{}
+
+	page, err, status := findPage(connection, "users", filter, query, userSortFields, func(cur *mongo.Cursor) error {
+		var user models.User
+
+		if err := cur.Decode(&user); err != nil {
+			return err
+		}
+
+		users = append(users, user)
+
+		return nil
+	})
+
+	if err != nil {
+		return []serializers.User{}, types.Page{}, err, status
+	}
+
+	return serializers.SerializeManyUsers(users), page, nil, constants.Success
+}
+
 func CreateUser(connection *mongo.Database, body io.Reader) (serializers.User, error, int) {
 	var user models.User
 
 	_ = json.NewDecoder(body).Decode(&user)
 
+	return createUser(connection, user)
+}
+
+// ErrEmailRegistered is returned by RegisterUser when the email already
+// belongs to an account. Callers must not reveal it to the client.
+var ErrEmailRegistered = fmt.Errorf("User email must be unique")
+
+// RegisterUser creates a self-service account with the given default role.
+// The account stays pending until its email is verified.
+func RegisterUser(connection *mongo.Database, body io.Reader, roleId primitive.ObjectID) (serializers.User, error, int) {
+	var registerBody types.RegisterBody
+
+	_ = json.NewDecoder(body).Decode(&registerBody)
+
+	if registerBody.Email == "" {
+		return serializers.User{}, fmt.Errorf("User email is required"), constants.UnprocessableEntity
+	}
+
+	if registerBody.Password == "" {
+		return serializers.User{}, fmt.Errorf("User password is required"), constants.UnprocessableEntity
+	}
+
+	hash, err := types.HashPassword(registerBody.Password)
+
+	if err != nil {
+		return serializers.User{}, err, constants.InternalServerError
+	}
+
+	if users, _, _ := QueryUsers(connection, bson.M{"email": registerBody.Email}); len(users) > 0 {
+		return serializers.SerializeOneUser(users[0]), ErrEmailRegistered, constants.UnprocessableEntity
+	}
+
+	user := models.User{
+		RoleID:    roleId,
+		RoleIDs:   []primitive.ObjectID{roleId},
+		Name:      registerBody.Name,
+		UserName:  registerBody.UserName,
+		Email:     registerBody.Email,
+		BirthDate: registerBody.BirthDate,
+		Password: types.Password{
+			Hash: hash,
+		},
+		Status: constants.UserPending,
+	}
+
+	return createUser(connection, user)
+}
+
+func createUser(connection *mongo.Database, user models.User) (serializers.User, error, int) {
 	if user.BirthDate.Time == time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC) {
 		return serializers.User{}, fmt.Errorf("Valid User Birthdate is required"), constants.UnprocessableEntity
 	}
 
-	_, err, _ := QueryRoles(connection, bson.M{"_id": user.RoleID})
+	user.RoleID, user.RoleIDs = MergeUserRoles(primitive.NilObjectID, nil, user.RoleID, user.RoleIDs)
+
+	if user.RoleID.IsZero() {
+		return serializers.User{}, fmt.Errorf("User Role doesn't exists, or is empty"), constants.NotFound
+	}
+
+	err := validateUserRoles(connection, user.RoleIDs)
 
 	if err != nil {
 		return serializers.User{}, fmt.Errorf("User Role doesn't exists, or is empty"), constants.NotFound
@@ -102,6 +196,18 @@ func CreateUser(connection *mongo.Database, body io.Reader) (serializers.User, e
 		return serializers.User{}, fmt.Errorf("User username must be unique"), constants.UnprocessableEntity
 	}
 
+	if user.Email != "" {
+		if _, err := mail.ParseAddress(user.Email); err != nil {
+			return serializers.User{}, fmt.Errorf("Valid User email is required"), constants.UnprocessableEntity
+		}
+
+		users, _, _ = QueryUsers(connection, bson.M{"email": user.Email})
+
+		if len(users) > 0 {
+			return serializers.User{}, fmt.Errorf("User email must be unique"), constants.UnprocessableEntity
+		}
+	}
+
 	err = InsertUser(connection, user)
 
 	if err != nil {
@@ -143,6 +249,88 @@ func GetUserRole(connection *mongo.Database, idParam string) (serializers.Role,
 	return serializers.SerializeOneRole(role[0]), err, constants.Success
 }
 
+// GetUserRoles lists the roles assigned to the user, without inherited ones.
+func GetUserRoles(connection *mongo.Database, idParam string) ([]serializers.Role, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	user, err, status := QueryUser(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return []serializers.Role{}, err, status
+	}
+
+	roles, err, _ := QueryRoles(connection, bson.M{"_id": bson.M{"$in": UserRoleIDs(user)}})
+
+	if err != nil {
+		return []serializers.Role{}, err, constants.InternalServerError
+	}
+
+	return serializers.SerializeManyRoles(roles), err, constants.Success
+}
+
+// UserRoleIDs returns every role of the user, falling back to the primary
+// role for users saved before multiple roles existed.
+func UserRoleIDs(user models.User) []primitive.ObjectID {
+	if len(user.RoleIDs) > 0 {
+		return user.RoleIDs
+	}
+
+	if user.RoleID.IsZero() {
+		return []primitive.ObjectID{}
+	}
+
+	return []primitive.ObjectID{user.RoleID}
+}
+
+// MergeUserRoles applies a role change to the current roles. A new role list
+// replaces the old one; a new primary role alone replaces the old primary.
+// The primary role is always part of the list.
+func MergeUserRoles(currentId primitive.ObjectID, currentIds []primitive.ObjectID, roleId primitive.ObjectID, roleIds []primitive.ObjectID) (primitive.ObjectID, []primitive.ObjectID) {
+	if roleIds == nil {
+		roleIds = []primitive.ObjectID{}
+
+		for _, id := range currentIds {
+			if id != currentId {
+				roleIds = append(roleIds, id)
+			}
+		}
+	} else if roleId.IsZero() && len(roleIds) > 0 {
+		roleId = roleIds[0]
+
+		if containsObjectID(roleIds, currentId) {
+			roleId = currentId
+		}
+	}
+
+	if !roleId.IsZero() && !containsObjectID(roleIds, roleId) {
+		roleIds = append([]primitive.ObjectID{roleId}, roleIds...)
+	}
+
+	return roleId, roleIds
+}
+
+func validateUserRoles(connection *mongo.Database, roleIds []primitive.ObjectID) error {
+	for _, roleId := range roleIds {
+		_, err, _ := QueryRole(connection, bson.M{"_id": roleId})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func containsObjectID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, item := range ids {
+		if item == id {
+			return true
+		}
+	}
+
+	return false
+}
+
 func GetUserPosts(connection *mongo.Database, idParam string) ([]serializers.Post, error, int) {
 	id, _ := primitive.ObjectIDFromHex(idParam)
 
@@ -161,6 +349,21 @@ func GetUserPosts(connection *mongo.Database, idParam string) ([]serializers.Pos
 	return serializers.SerializeManyPosts(posts), err, constants.Success
 }
 
+// ListUserPosts returns one page of the visible posts of a user.
+func ListUserPosts(connection *mongo.Database, idParam string, visibility bson.M, query types.ListQuery) ([]serializers.Post, types.Page, error, int) {
+	id, _ := primitive.ObjectIDFromHex(idParam)
+
+	user, err, status := QueryUser(connection, bson.M{"_id": id})
+
+	if err != nil {
+		return []serializers.Post{}, types.Page{}, err, status
+	}
+
+	query.Author = user.ID
+
+	return listPosts(connection, visibility, query)
+}
+
 func UpdateUser(connection *mongo.Database, idParam string, body io.Reader) (serializers.User, error, int) {
 	var user models.User
 
@@ -168,9 +371,25 @@ func UpdateUser(connection *mongo.Database, idParam string, body io.Reader) (ser
 
 	_ = json.NewDecoder(body).Decode(&user)
 
+	return updateUser(connection, id, user)
+}
+
+// UpdateProfile lets users edit their own account. Username and email are
+// left alone: the username identifies the user's tokens and a new email must
+// be verified first, see ConfirmEmail.
+func UpdateProfile(connection *mongo.Database, id primitive.ObjectID, profileBody types.ProfileBody) (serializers.User, error, int) {
+	user := models.User{
+		Name:      profileBody.Name,
+		BirthDate: profileBody.BirthDate,
+	}
+
+	return updateUser(connection, id, user)
+}
+
+func updateUser(connection *mongo.Database, id primitive.ObjectID, user models.User) (serializers.User, error, int) {
 	aux1, err, _ := QueryUsers(connection, bson.M{"_id": id})
 
-	if err != nil {
+	if err != nil || len(aux1) == 0 {
 		return serializers.User{}, fmt.Errorf("Requested User doesn't exist"), constants.NotFound
 	}
 
@@ -180,8 +399,14 @@ func UpdateUser(connection *mongo.Database, idParam string, body io.Reader) (ser
 		return serializers.User{}, fmt.Errorf("A User with this username already exists"), constants.UnprocessableEntity
 	}
 
-	if user.RoleID.Hex() != "000000000000000000000000" {
-		_, err, _ = QueryRoles(connection, bson.M{"_id": user.RoleID})
+	if user.RoleIDs != nil && len(user.RoleIDs) == 0 {
+		return serializers.User{}, fmt.Errorf("A User needs at least one Role"), constants.UnprocessableEntity
+	}
+
+	if !user.RoleID.IsZero() || user.RoleIDs != nil {
+		user.RoleID, user.RoleIDs = MergeUserRoles(aux1[0].RoleID, UserRoleIDs(aux1[0]), user.RoleID, user.RoleIDs)
+
+		err = validateUserRoles(connection, user.RoleIDs)
 
 		if err != nil {
 			return serializers.User{}, fmt.Errorf("Valid User Role is required"), constants.UnprocessableEntity
@@ -198,6 +423,20 @@ func UpdateUser(connection *mongo.Database, idParam string, body io.Reader) (ser
 		setObj["username"] = user.UserName
 	}
 
+	if user.Email != "" {
+		if _, err := mail.ParseAddress(user.Email); err != nil {
+			return serializers.User{}, fmt.Errorf("Valid User email is required"), constants.UnprocessableEntity
+		}
+
+		aux3, err, _ := QueryUsers(connection, bson.M{"email": user.Email})
+
+		if err == nil && len(aux3) > 0 && aux1[0].ID != aux3[0].ID {
+			return serializers.User{}, fmt.Errorf("A User with this email already exists"), constants.UnprocessableEntity
+		}
+
+		setObj["email"] = user.Email
+	}
+
 	if user.BirthDate.Time != time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC) {
 		setObj["birthDate"] = user.BirthDate
 	}
@@ -206,8 +445,9 @@ func UpdateUser(connection *mongo.Database, idParam string, body io.Reader) (ser
 		setObj["password"] = user.Password
 	}
 
-	if user.RoleID.Hex() != "000000000000000000000000" {
+	if !user.RoleID.IsZero() {
 		setObj["_roleId"] = user.RoleID
+		setObj["_roleIds"] = user.RoleIDs
 	}
 
 	update := bson.M{
@@ -242,5 +482,45 @@ func DeleteUser(connection *mongo.Database, idParam string) (serializers.User, e
 		return serializers.User{}, fmt.Errorf("Requested User doesn't exist"), constants.NotFound
 	}
 
+	_, err = connection.Collection("groups").UpdateMany(context.TODO(), bson.M{"members._userId": id}, bson.M{
+		"$pull": bson.M{
+			"members": bson.M{"_userId": id},
+		},
+	})
+
+	if err != nil {
+		return serializers.User{}, err, constants.InternalServerError
+	}
+
 	return serializers.User{}, err, constants.Success
 }
+
+// ConfirmEmail activates the user of a verification and switches them to the
+// email it was sent to, unless another account took that email meanwhile.
+func ConfirmEmail(connection *mongo.Database, verification models.EmailVerification) (error, int) {
+	setObj := bson.M{
+		"status": constants.UserActive,
+	}
+
+	if verification.Email != "" {
+		users, err, status := QueryUsers(connection, bson.M{"email": verification.Email, "_id": bson.M{"$ne": verification.UserID}})
+
+		if err != nil {
+			return err, status
+		}
+
+		if len(users) > 0 {
+			return fmt.Errorf("A User with this email already exists"), constants.UnprocessableEntity
+		}
+
+		setObj["email"] = verification.Email
+	}
+
+	_, err := connection.Collection("users").UpdateOne(context.TODO(), bson.M{"_id": verification.UserID}, bson.M{"$set": setObj})
+
+	if err != nil {
+		return err, constants.InternalServerError
+	}
+
+	return nil, constants.Success
+}
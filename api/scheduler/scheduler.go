@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	helpers "auth_blog_service/helpers"
+	repositories "auth_blog_service/repositories"
+)
+
+const leaseName = "post-publisher"
+
+// Scheduler publishes posts once their publishAt has passed. Every replica
+// runs one, but only the holder of the Mongo lease does the work, so
+// publication hooks fire once per post.
+type Scheduler struct {
+	Connection *mongo.Database
+	Interval   time.Duration
+	Owner      string
+}
+
+// NewScheduler reads the tick interval from SCHEDULER_INTERVAL, a Go
+// duration such as "30s", defaulting to a minute.
+func NewScheduler(connection *mongo.Database) (*Scheduler, error) {
+	interval := time.Minute
+
+	if value := os.Getenv("SCHEDULER_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("SCHEDULER_INTERVAL must be a positive duration")
+		}
+
+		interval = parsed
+	}
+
+	owner, err := helpers.GenerateRandomToken(12)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{
+		Connection: connection,
+		Interval:   interval,
+		Owner:      hostname + "-" + owner,
+	}, nil
+}
+
+// Run ticks until stop is closed, then hands the lease over.
+func (scheduler *Scheduler) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(scheduler.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := scheduler.Tick(time.Now()); err != nil {
+			fmt.Println("Scheduler failed:", err)
+		}
+
+		select {
+		case <-stop:
+			repositories.ReleaseLease(scheduler.Connection, leaseName, scheduler.Owner)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick publishes the posts due at now, if this scheduler holds the lease.
+// The lease outlives two intervals so a single slow tick doesn't lose it.
+func (scheduler *Scheduler) Tick(now time.Time) error {
+	acquired, err := repositories.AcquireLease(scheduler.Connection, leaseName, scheduler.Owner, 2*scheduler.Interval)
+
+	if err != nil || !acquired {
+		return err
+	}
+
+	posts, err := repositories.PublishDuePosts(scheduler.Connection, now)
+
+	for _, post := range posts {
+		fmt.Println("Scheduler published post", post.ID.Hex())
+
+		helpers.IndexPost(scheduler.Connection, post)
+		helpers.NotifyPostPublished(post)
+	}
+
+	return err
+}
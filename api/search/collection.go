@@ -0,0 +1,180 @@
+package search
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// maxCandidates caps the documents ranked in Go when a query has no text
+// index part to rank them.
+const maxCandidates = 1000
+
+type collectionDocument struct {
+	ID      primitive.ObjectID `bson:"_id"`
+	Title   string             `bson:"title"`
+	Body    string             `bson:"body"`
+	Status  string             `bson:"status"`
+	UserID  primitive.ObjectID `bson:"_userId"`
+	GroupID primitive.ObjectID `bson:"_groupId"`
+	Score   float64            `bson:"score"`
+}
+
+// CollectionBackend searches the posts collection through its text index.
+// Mongo keeps that index up to date, so Index and Remove do nothing.
+type CollectionBackend struct {
+	Connection *mongo.Database
+}
+
+func (backend *CollectionBackend) Search(query Query, filter *Filter, limit int) ([]Hit, error) {
+	conditions := []bson.M{}
+	text := query.TextSearch()
+
+	if text != "" {
+		conditions = append(conditions, bson.M{"$text": bson.M{"$search": text}})
+	}
+
+	for _, prefix := range query.Prefixes {
+		pattern := bson.M{"$regex": `\b` + regexp.QuoteMeta(prefix), "$options": "i"}
+
+		conditions = append(conditions, bson.M{"$or": []bson.M{{"title": pattern}, {"body": pattern}}})
+	}
+
+	if len(conditions) == 0 {
+		return []Hit{}, nil
+	}
+
+	if filter != nil {
+		visible := []bson.M{{"status": bson.M{"$in": append([]string{}, filter.Statuses...)}}}
+
+		if ownerId, err := primitive.ObjectIDFromHex(filter.OwnerID); err == nil {
+			visible = append(visible, bson.M{"_userId": ownerId})
+		}
+
+		groupIds := []primitive.ObjectID{}
+
+		for _, groupId := range filter.GroupIDs {
+			if id, err := primitive.ObjectIDFromHex(groupId); err == nil {
+				groupIds = append(groupIds, id)
+			}
+		}
+
+		if len(groupIds) > 0 {
+			visible = append(visible, bson.M{"_groupId": bson.M{"$in": groupIds}})
+		}
+
+		conditions = append(conditions, bson.M{"$or": visible})
+	}
+
+	opts := options.Find().SetLimit(maxCandidates)
+
+	if text != "" {
+		score := bson.M{"$meta": "textScore"}
+
+		opts = opts.SetProjection(bson.M{"title": 1, "body": 1, "score": score}).SetSort(bson.M{"score": score}).SetLimit(int64(limit))
+	}
+
+	cur, err := backend.Connection.Collection("posts").Find(context.TODO(), bson.M{"$and": conditions}, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cur.Close(context.TODO())
+
+	documents := []collectionDocument{}
+
+	if err := cur.All(context.TODO(), &documents); err != nil {
+		return nil, err
+	}
+
+	hits := []Hit{}
+
+	for _, item := range documents {
+		document := Document{ID: item.ID.Hex(), Title: item.Title, Body: item.Body}
+
+		if text == "" {
+			item.Score = Relevance(document, query)
+		}
+
+		hits = append(hits, Hit{
+			ID:      document.ID,
+			Score:   item.Score,
+			Title:   Highlight(document.Title, query, 0),
+			Snippet: Highlight(document.Body, query, SnippetLength),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+func (backend *CollectionBackend) Index(document Document) error {
+	return nil
+}
+
+func (backend *CollectionBackend) Remove(id string) error {
+	return nil
+}
+
+// LoadCollection indexes every post of the collection.
+func (index *Index) LoadCollection(connection *mongo.Database) error {
+	cur, err := connection.Collection("posts").Find(context.TODO(), bson.M{})
+
+	if err != nil {
+		return err
+	}
+
+	defer cur.Close(context.TODO())
+
+	documents := []collectionDocument{}
+
+	if err := cur.All(context.TODO(), &documents); err != nil {
+		return err
+	}
+
+	for _, item := range documents {
+		index.Index(Document{ID: item.ID.Hex(), Title: item.Title, Body: item.Body, Status: item.Status, OwnerID: item.UserID.Hex(), GroupID: groupHex(item.GroupID)})
+	}
+
+	return nil
+}
+
+// NewBackend picks the search backend with SEARCH_BACKEND: "memory" indexes
+// the posts in process at startup, anything else uses the Mongo text index.
+func NewBackend(connection *mongo.Database) (Backend, error) {
+	if os.Getenv("SEARCH_BACKEND") == "memory" {
+		index := NewIndex()
+
+		if err := index.LoadCollection(connection); err != nil {
+			return nil, err
+		}
+
+		return index, nil
+	}
+
+	return &CollectionBackend{Connection: connection}, nil
+}
+
+// groupHex is the GroupID of a document, empty for posts outside a group so
+// they never match a filter.
+func groupHex(groupId primitive.ObjectID) string {
+	if groupId.IsZero() {
+		return ""
+	}
+
+	return groupId.Hex()
+}
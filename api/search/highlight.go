@@ -0,0 +1,162 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// SnippetLength is the rough number of characters of a body snippet.
+const SnippetLength = 160
+
+const MarkStart = "<mark>"
+const MarkEnd = "</mark>"
+
+// Highlight escapes text as HTML and wraps the words matching the query in
+// <mark>. A positive width cuts the text to a snippet starting shortly
+// before the first match.
+func Highlight(text string, query Query, width int) string {
+	tokens := tokenize(text)
+	marked := matchTokens(tokens, query)
+
+	start, end := 0, len(text)
+
+	if width > 0 && len(text) > width {
+		start, end = snippetBounds(text, tokens, marked, width)
+	}
+
+	var builder strings.Builder
+
+	if start > 0 {
+		builder.WriteString("…")
+	}
+
+	cursor := start
+
+	for i, token := range tokens {
+		if token.Start < start || token.End > end || !marked[i] {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(text[cursor:token.Start]))
+		builder.WriteString(MarkStart)
+		builder.WriteString(html.EscapeString(text[token.Start:token.End]))
+		builder.WriteString(MarkEnd)
+
+		cursor = token.End
+	}
+
+	builder.WriteString(html.EscapeString(text[cursor:end]))
+
+	if end < len(text) {
+		builder.WriteString("…")
+	}
+
+	return builder.String()
+}
+
+// Relevance counts the query words of a document, title words twice. It
+// ranks matches when no better score is available.
+func Relevance(document Document, query Query) float64 {
+	score := 0.0
+
+	for _, matched := range matchTokens(tokenize(document.Title), query) {
+		if matched {
+			score += 2
+		}
+	}
+
+	for _, matched := range matchTokens(tokenize(document.Body), query) {
+		if matched {
+			score++
+		}
+	}
+
+	return score
+}
+
+func matchTokens(tokens []token, query Query) []bool {
+	marked := make([]bool, len(tokens))
+
+	for i, token := range tokens {
+		marked[i] = matchWord(token.Word, query)
+	}
+
+	for _, phrase := range query.Phrases {
+		for i := 0; i+len(phrase) <= len(tokens); i++ {
+			if matchPhraseAt(tokens, i, phrase) {
+				for j := range phrase {
+					marked[i+j] = true
+				}
+			}
+		}
+	}
+
+	return marked
+}
+
+func matchWord(word string, query Query) bool {
+	for _, term := range query.Terms {
+		if word == term {
+			return true
+		}
+	}
+
+	for _, prefix := range query.Prefixes {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchPhraseAt(tokens []token, i int, phrase []string) bool {
+	for j, word := range phrase {
+		if tokens[i+j].Word != word {
+			return false
+		}
+	}
+
+	return true
+}
+
+// snippetBounds picks a window of about width bytes around the first marked
+// token, cut on word boundaries.
+func snippetBounds(text string, tokens []token, marked []bool, width int) (int, int) {
+	first := 0
+
+	for i, token := range tokens {
+		if marked[i] {
+			first = token.Start
+			break
+		}
+	}
+
+	start := first - width/4
+
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + width
+
+	if end > len(text) {
+		end = len(text)
+	}
+
+	for _, token := range tokens {
+		if start > 0 && token.End > start {
+			start = token.Start
+			break
+		}
+	}
+
+	for i := len(tokens) - 1; i >= 0 && end < len(text); i-- {
+		if tokens[i].Start < end {
+			end = tokens[i].End
+			break
+		}
+	}
+
+	return start, end
+}
@@ -0,0 +1,246 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// titleWeight makes title words count more than body words, like the
+// weights of the Mongo text index.
+const titleWeight = 2.0
+
+type posting struct {
+	title []int
+	body  []int
+}
+
+// Index is an in-memory inverted index ranking documents by TF-IDF. It does
+// no stemming, unlike Mongo, so "posts" doesn't match "post" unless searched
+// as the prefix "post*".
+type Index struct {
+	mutex     sync.RWMutex
+	documents map[string]Document
+	postings  map[string]map[string]*posting
+}
+
+func NewIndex() *Index {
+	return &Index{
+		documents: map[string]Document{},
+		postings:  map[string]map[string]*posting{},
+	}
+}
+
+func (index *Index) Index(document Document) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(document.ID)
+
+	index.documents[document.ID] = document
+
+	for position, word := range Words(document.Title) {
+		posting := index.posting(word, document.ID)
+		posting.title = append(posting.title, position)
+	}
+
+	for position, word := range Words(document.Body) {
+		posting := index.posting(word, document.ID)
+		posting.body = append(posting.body, position)
+	}
+
+	return nil
+}
+
+func (index *Index) Remove(id string) error {
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	index.remove(id)
+
+	return nil
+}
+
+func (index *Index) Search(query Query, filter *Filter, limit int) ([]Hit, error) {
+	index.mutex.RLock()
+	defer index.mutex.RUnlock()
+
+	scores := map[string]float64{}
+	termMatches := map[string]bool{}
+	required := []map[string]bool{}
+
+	for _, term := range query.Terms {
+		for id := range index.score(term, scores) {
+			termMatches[id] = true
+		}
+	}
+
+	for _, prefix := range query.Prefixes {
+		matches := map[string]bool{}
+
+		for word := range index.postings {
+			if strings.HasPrefix(word, prefix) {
+				for id := range index.score(word, scores) {
+					matches[id] = true
+				}
+			}
+		}
+
+		required = append(required, matches)
+	}
+
+	for _, phrase := range query.Phrases {
+		required = append(required, index.scorePhrase(phrase, scores))
+	}
+
+	hits := []Hit{}
+
+	for id, score := range scores {
+		document := index.documents[id]
+
+		if !filter.Allows(document) || !index.matches(id, termMatches, required, query) {
+			continue
+		}
+
+		hits = append(hits, Hit{
+			ID:      id,
+			Score:   score,
+			Title:   Highlight(document.Title, query, 0),
+			Snippet: Highlight(document.Body, query, SnippetLength),
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+
+		return hits[i].ID < hits[j].ID
+	})
+
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+func (index *Index) matches(id string, termMatches map[string]bool, required []map[string]bool, query Query) bool {
+	for _, matches := range required {
+		if !matches[id] {
+			return false
+		}
+	}
+
+	if len(query.Terms) > 0 && len(query.Phrases) == 0 {
+		return termMatches[id]
+	}
+
+	return true
+}
+
+// score adds the TF-IDF of word to every document containing it and returns
+// those documents.
+func (index *Index) score(word string, scores map[string]float64) map[string]*posting {
+	postings := index.postings[word]
+	idf := index.idf(len(postings))
+
+	for id, posting := range postings {
+		scores[id] += idf * (titleWeight*float64(len(posting.title)) + float64(len(posting.body)))
+	}
+
+	return postings
+}
+
+func (index *Index) scorePhrase(phrase []string, scores map[string]float64) map[string]bool {
+	matches := map[string]bool{}
+
+	for id, first := range index.postings[phrase[0]] {
+		title := index.countPhrase(id, phrase, first.title, func(posting *posting) []int { return posting.title })
+		body := index.countPhrase(id, phrase, first.body, func(posting *posting) []int { return posting.body })
+
+		if title+body == 0 {
+			continue
+		}
+
+		matches[id] = true
+
+		for _, word := range phrase {
+			scores[id] += index.idf(len(index.postings[word])) * (titleWeight*float64(title) + float64(body))
+		}
+	}
+
+	return matches
+}
+
+func (index *Index) countPhrase(id string, phrase []string, starts []int, positions func(*posting) []int) int {
+	count := 0
+
+	for _, start := range starts {
+		found := true
+
+		for offset, word := range phrase[1:] {
+			posting, ok := index.postings[word][id]
+
+			if !ok || !containsInt(positions(posting), start+offset+1) {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (index *Index) idf(documents int) float64 {
+	if documents == 0 {
+		return 0
+	}
+
+	return math.Log(1 + float64(len(index.documents))/float64(documents))
+}
+
+func (index *Index) posting(word string, id string) *posting {
+	if index.postings[word] == nil {
+		index.postings[word] = map[string]*posting{}
+	}
+
+	if index.postings[word][id] == nil {
+		index.postings[word][id] = &posting{}
+	}
+
+	return index.postings[word][id]
+}
+
+func (index *Index) remove(id string) {
+	document, ok := index.documents[id]
+
+	if !ok {
+		return
+	}
+
+	for _, word := range append(Words(document.Title), Words(document.Body)...) {
+		delete(index.postings[word], id)
+
+		if len(index.postings[word]) == 0 {
+			delete(index.postings, word)
+		}
+	}
+
+	delete(index.documents, id)
+}
+
+func containsInt(items []int, item int) bool {
+	for _, existing := range items {
+		if existing == item {
+			return true
+		}
+	}
+
+	return false
+}
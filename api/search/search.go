@@ -0,0 +1,174 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Document is the searchable part of a post, with what Filter looks at.
+type Document struct {
+	ID      string
+	Title   string
+	Body    string
+	Status  string
+	OwnerID string
+	GroupID string
+}
+
+// Filter keeps the documents with one of Statuses, owned by OwnerID or in one
+// of GroupIDs. A nil Filter keeps every document.
+type Filter struct {
+	Statuses []string
+	OwnerID  string
+	GroupIDs []string
+}
+
+func (filter *Filter) Allows(document Document) bool {
+	if filter == nil {
+		return true
+	}
+
+	if filter.OwnerID != "" && document.OwnerID == filter.OwnerID {
+		return true
+	}
+
+	for _, status := range filter.Statuses {
+		if document.Status == status {
+			return true
+		}
+	}
+
+	for _, groupId := range filter.GroupIDs {
+		if document.GroupID == groupId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Hit is a matching document with its relevance and highlighted text.
+type Hit struct {
+	ID      string
+	Score   float64
+	Title   string
+	Snippet string
+}
+
+// Backend finds documents matching a query and the filter, best first. The
+// filter is applied before the limit. Backends that keep their own index are
+// told about every change of a document.
+type Backend interface {
+	Search(query Query, filter *Filter, limit int) ([]Hit, error)
+	Index(document Document) error
+	Remove(id string) error
+}
+
+// Query is a parsed search string. Any term may match, every quoted phrase
+// must, and so must every prefix ending with "*". As with Mongo text search,
+// terms only rank the results once a phrase is given.
+type Query struct {
+	Terms    []string
+	Phrases  [][]string
+	Prefixes []string
+}
+
+func ParseQuery(q string) Query {
+	query := Query{}
+
+	for i, segment := range strings.Split(q, `"`) {
+		if i%2 == 1 {
+			if words := Words(segment); len(words) > 0 {
+				query.Phrases = append(query.Phrases, words)
+			}
+
+			continue
+		}
+
+		for _, field := range strings.Fields(segment) {
+			words := Words(field)
+
+			if len(words) == 0 {
+				continue
+			}
+
+			if strings.HasSuffix(field, "*") {
+				query.Prefixes = appendUnique(query.Prefixes, words[len(words)-1])
+				words = words[:len(words)-1]
+			}
+
+			for _, word := range words {
+				query.Terms = appendUnique(query.Terms, word)
+			}
+		}
+	}
+
+	return query
+}
+
+func (query Query) Empty() bool {
+	return len(query.Terms) == 0 && len(query.Phrases) == 0 && len(query.Prefixes) == 0
+}
+
+// TextSearch renders the terms and phrases as a Mongo $search string.
+func (query Query) TextSearch() string {
+	parts := append([]string{}, query.Terms...)
+
+	for _, phrase := range query.Phrases {
+		parts = append(parts, `"`+strings.Join(phrase, " ")+`"`)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+type token struct {
+	Word  string
+	Start int
+	End   int
+}
+
+// tokenize splits text into lowercase words of letters and digits, keeping
+// their byte offsets.
+func tokenize(text string) []token {
+	tokens := []token{}
+	start := -1
+
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+
+		if inWord && start < 0 {
+			start = i
+		}
+
+		if !inWord && start >= 0 {
+			tokens = append(tokens, token{Word: strings.ToLower(text[start:i]), Start: start, End: i})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{Word: strings.ToLower(text[start:]), Start: start, End: len(text)})
+	}
+
+	return tokens
+}
+
+func Words(text string) []string {
+	words := []string{}
+
+	for _, token := range tokenize(text) {
+		words = append(words, token.Word)
+	}
+
+	return words
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+
+	return append(items, item)
+}
@@ -0,0 +1,136 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	query := ParseQuery(`Go "error handling" mongo* go`)
+
+	if reflect.DeepEqual(query.Terms, []string{"go"}) && reflect.DeepEqual(query.Phrases, [][]string{{"error", "handling"}}) && reflect.DeepEqual(query.Prefixes, []string{"mongo"}) {
+		t.Log("ParseQuery 01 passed")
+	} else {
+		t.Error("ParseQuery 01 failed")
+	}
+
+	if ParseQuery(` " * `).Empty() {
+		t.Log("ParseQuery 02 passed")
+	} else {
+		t.Error("ParseQuery 02 failed")
+	}
+
+	if ParseQuery(`go "error handling"`).TextSearch() == `go "error handling"` {
+		t.Log("ParseQuery 03 passed")
+	} else {
+		t.Error("ParseQuery 03 failed")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	if Highlight("Go <generics> are here", ParseQuery("gener*"), 0) == "Go &lt;<mark>generics</mark>&gt; are here" {
+		t.Log("Highlight 01 passed")
+	} else {
+		t.Error("Highlight 01 failed")
+	}
+
+	if Highlight("error handling and error values", ParseQuery(`"error handling"`), 0) == "<mark>error</mark> <mark>handling</mark> and error values" {
+		t.Log("Highlight 02 passed")
+	} else {
+		t.Error("Highlight 02 failed")
+	}
+
+	text := strings.Repeat("filler ", 60) + "needle " + strings.Repeat("filler ", 60)
+	snippet := Highlight(text, ParseQuery("needle"), SnippetLength)
+
+	if strings.HasPrefix(snippet, "…") && strings.HasSuffix(snippet, "…") && strings.Contains(snippet, "<mark>needle</mark>") && len(snippet) < SnippetLength+40 {
+		t.Log("Highlight 03 passed")
+	} else {
+		t.Error("Highlight 03 failed")
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	index := NewIndex()
+
+	index.Index(Document{ID: "1", Title: "Error handling in Go", Body: "Go errors are values."})
+	index.Index(Document{ID: "2", Title: "Mongo indexes", Body: "Text indexes support error handling too."})
+	index.Index(Document{ID: "3", Title: "Cooking", Body: "Nothing about handling errors here."})
+
+	hits, _ := index.Search(ParseQuery("go"), nil, 10)
+
+	if len(hits) == 1 && hits[0].ID == "1" && hits[0].Title == "Error handling in <mark>Go</mark>" {
+		t.Log("IndexSearch 01 passed")
+	} else {
+		t.Error("IndexSearch 01 failed")
+	}
+
+	hits, _ = index.Search(ParseQuery(`"error handling"`), nil, 10)
+
+	if len(hits) == 2 && hits[0].ID == "1" && hits[1].ID == "2" {
+		t.Log("IndexSearch 02 passed")
+	} else {
+		t.Error("IndexSearch 02 failed")
+	}
+
+	hits, _ = index.Search(ParseQuery("error*"), nil, 10)
+
+	if len(hits) == 3 {
+		t.Log("IndexSearch 03 passed")
+	} else {
+		t.Error("IndexSearch 03 failed")
+	}
+
+	hits, _ = index.Search(ParseQuery("cooking mongo"), nil, 1)
+
+	if len(hits) == 1 {
+		t.Log("IndexSearch 04 passed")
+	} else {
+		t.Error("IndexSearch 04 failed")
+	}
+
+	index.Remove("1")
+	index.Index(Document{ID: "2", Title: "Mongo", Body: "Replaced."})
+
+	hits, _ = index.Search(ParseQuery(`"error handling" go`), nil, 10)
+
+	if len(hits) == 0 {
+		t.Log("IndexSearch 05 passed")
+	} else {
+		t.Error("IndexSearch 05 failed")
+	}
+}
+
+func TestIndexSearchFilter(t *testing.T) {
+	index := NewIndex()
+
+	index.Index(Document{ID: "1", Title: "Go drafts", Status: "draft", OwnerID: "a"})
+	index.Index(Document{ID: "2", Title: "Go drafts", Status: "draft", OwnerID: "b"})
+	index.Index(Document{ID: "3", Title: "Go", Status: "published", OwnerID: "b"})
+	index.Index(Document{ID: "4", Title: "Go drafts", Status: "draft", OwnerID: "c", GroupID: "g"})
+
+	hits, _ := index.Search(ParseQuery("go drafts"), &Filter{Statuses: []string{"published"}}, 1)
+
+	if len(hits) == 1 && hits[0].ID == "3" {
+		t.Log("IndexSearchFilter 01 passed")
+	} else {
+		t.Error("IndexSearchFilter 01 failed")
+	}
+
+	hits, _ = index.Search(ParseQuery("go"), &Filter{Statuses: []string{"published"}, OwnerID: "a"}, 10)
+
+	if len(hits) == 2 && hits[0].ID != "2" && hits[1].ID != "2" {
+		t.Log("IndexSearchFilter 02 passed")
+	} else {
+		t.Error("IndexSearchFilter 02 failed")
+	}
+
+	hits, _ = index.Search(ParseQuery("go drafts"), &Filter{Statuses: []string{"published"}, OwnerID: "a", GroupIDs: []string{"g"}}, 10)
+
+	if len(hits) == 3 {
+		t.Log("IndexSearchFilter 03 passed")
+	} else {
+		t.Error("IndexSearchFilter 03 failed")
+	}
+}
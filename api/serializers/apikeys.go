@@ -0,0 +1,52 @@
+package serializers
+
+import (
+	"time"
+
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type APIKey struct {
+	ID           primitive.ObjectID `json:"_id,omitempty"`
+	Name         string             `json:"name"`
+	Key          string             `json:"key,omitempty"`
+	Prefix       string             `json:"prefix"`
+	Permissions  []string           `json:"permissions"`
+	CreatedDate  string             `json:"createdDate"`
+	LastUsedDate string             `json:"lastUsedDate,omitempty"`
+	ExpiresDate  string             `json:"expiresDate,omitempty"`
+	Active       bool               `json:"active"`
+}
+
+func SerializeOneAPIKey(apiKey models.APIKey) APIKey {
+	serialized := APIKey{
+		ID:          apiKey.ID,
+		Name:        apiKey.Name,
+		Prefix:      apiKey.Prefix,
+		Permissions: apiKey.Permissions,
+		CreatedDate: apiKey.CreatedDate.Time.Format("2006-01-02"),
+		Active:      apiKey.Active,
+	}
+
+	if !apiKey.LastUsedDate.IsZero() {
+		serialized.LastUsedDate = apiKey.LastUsedDate.Format(time.RFC3339)
+	}
+
+	if !apiKey.ExpiresDate.IsZero() {
+		serialized.ExpiresDate = apiKey.ExpiresDate.Format("2006-01-02")
+	}
+
+	return serialized
+}
+
+func SerializeManyAPIKeys(apiKeys []models.APIKey) []APIKey {
+	var apiKeysArray []APIKey
+
+	for _, apiKey := range apiKeys {
+		apiKeysArray = append(apiKeysArray, SerializeOneAPIKey(apiKey))
+	}
+
+	return apiKeysArray
+}
@@ -0,0 +1,41 @@
+package serializers
+
+import (
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AuditRecord struct {
+	ID             primitive.ObjectID `json:"_id,omitempty"`
+	ImpersonatorID primitive.ObjectID `json:"_impersonatorId"`
+	UserID         primitive.ObjectID `json:"_userId"`
+	Method         string             `json:"method"`
+	Path           string             `json:"path"`
+	Status         int                `json:"status"`
+	IP             string             `json:"ip,omitempty"`
+	CreatedDate    string             `json:"createdDate,omitempty"`
+}
+
+func SerializeOneAuditRecord(record models.AuditRecord) AuditRecord {
+	return AuditRecord{
+		ID:             record.ID,
+		ImpersonatorID: record.ImpersonatorID,
+		UserID:         record.UserID,
+		Method:         record.Method,
+		Path:           record.Path,
+		Status:         record.Status,
+		IP:             record.IP,
+		CreatedDate:    record.CreatedDate.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func SerializeManyAuditRecords(records []models.AuditRecord) []AuditRecord {
+	recordsArray := []AuditRecord{}
+
+	for _, record := range records {
+		recordsArray = append(recordsArray, SerializeOneAuditRecord(record))
+	}
+
+	return recordsArray
+}
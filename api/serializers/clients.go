@@ -0,0 +1,44 @@
+package serializers
+
+import (
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Client struct {
+	ID           primitive.ObjectID `json:"_id,omitempty"`
+	ClientID     string             `json:"clientId"`
+	ClientSecret string             `json:"clientSecret,omitempty"`
+	Name         string             `json:"name"`
+	RedirectURIs []string           `json:"redirectUris"`
+	GrantTypes   []string           `json:"grantTypes"`
+	Scopes       []string           `json:"scopes"`
+	Public       bool               `json:"public"`
+	OwnerID      primitive.ObjectID `json:"_ownerId"`
+	CreatedDate  string             `json:"createdDate"`
+}
+
+func SerializeOneClient(client models.Client) Client {
+	return Client{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIs,
+		GrantTypes:   client.GrantTypes,
+		Scopes:       client.Scopes,
+		Public:       client.Public,
+		OwnerID:      client.OwnerID,
+		CreatedDate:  client.CreatedDate.Time.Format("2006-01-02"),
+	}
+}
+
+func SerializeManyClients(clients []models.Client) []Client {
+	var clientsArray []Client
+
+	for _, client := range clients {
+		clientsArray = append(clientsArray, SerializeOneClient(client))
+	}
+
+	return clientsArray
+}
@@ -0,0 +1,47 @@
+package serializers
+
+import (
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Group struct {
+	ID          primitive.ObjectID `json:"_id,omitempty"`
+	Name        string             `json:"name"`
+	Members     []GroupMember      `json:"members"`
+	CreatedDate string             `json:"createdDate"`
+}
+
+type GroupMember struct {
+	UserID primitive.ObjectID `json:"_userId"`
+	RoleID primitive.ObjectID `json:"_roleId"`
+}
+
+func SerializeOneGroup(group models.Group) Group {
+	members := []GroupMember{}
+
+	for _, member := range group.Members {
+		members = append(members, GroupMember{
+			UserID: member.UserID,
+			RoleID: member.RoleID,
+		})
+	}
+
+	return Group{
+		ID:          group.ID,
+		Name:        group.Name,
+		Members:     members,
+		CreatedDate: group.CreatedDate.Time.Format("2006-01-02"),
+	}
+}
+
+func SerializeManyGroups(groups []models.Group) []Group {
+	var groupsArray []Group
+
+	for _, group := range groups {
+		groupsArray = append(groupsArray, SerializeOneGroup(group))
+	}
+
+	return groupsArray
+}
@@ -7,21 +7,39 @@ import (
 )
 
 type Post struct {
-	ID          primitive.ObjectID `json:"_id,omitempty"`
-	UserID      primitive.ObjectID `json:"_userId"`
-	Title       string             `json:"title"`
-	Body        string             `json:"body"`
-	CreatedDate string             `json:"createdDate"`
+	ID            primitive.ObjectID `json:"_id,omitempty"`
+	UserID        primitive.ObjectID `json:"_userId"`
+	GroupID       primitive.ObjectID `json:"_groupId,omitempty"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	CreatedDate   string             `json:"createdDate"`
+	Status        string             `json:"status"`
+	PublishedDate string             `json:"publishedDate,omitempty"`
+	PublishAt     string             `json:"publishAt,omitempty"`
+	Revision      int                `json:"revision"`
 }
 
 func SerializeOnePost(post models.Post) Post {
-	return Post{
+	serialized := Post{
 		ID:          post.ID,
 		UserID:      post.UserID,
+		GroupID:     post.GroupID,
 		Title:       post.Title,
 		Body:        post.Body,
 		CreatedDate: post.CreatedDate.Time.Format("2006-01-02"),
+		Status:      post.Status,
+		Revision:    post.Revision,
 	}
+
+	if !post.PublishedDate.IsZero() {
+		serialized.PublishedDate = post.PublishedDate.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	if !post.PublishAt.IsZero() {
+		serialized.PublishAt = post.PublishAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return serialized
 }
 
 func SerializeManyPosts(posts []models.Post) []Post {
@@ -33,3 +51,27 @@ func SerializeManyPosts(posts []models.Post) []Post {
 
 	return postsArray
 }
+
+// PostHit is a post found by search, with its relevance and the matching
+// words of its title and body marked.
+type PostHit struct {
+	Post
+	Score      float64        `json:"score"`
+	Highlights PostHighlights `json:"highlights"`
+}
+
+type PostHighlights struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func SerializeOnePostHit(post models.Post, score float64, title string, body string) PostHit {
+	return PostHit{
+		Post:  SerializeOnePost(post),
+		Score: score,
+		Highlights: PostHighlights{
+			Title: title,
+			Body:  body,
+		},
+	}
+}
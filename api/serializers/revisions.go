@@ -0,0 +1,43 @@
+package serializers
+
+import (
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PostRevision struct {
+	Number       int                `json:"number"`
+	UserID       primitive.ObjectID `json:"_userId"`
+	Title        string             `json:"title"`
+	Body         string             `json:"body"`
+	RestoredFrom int                `json:"restoredFrom,omitempty"`
+	CreatedDate  string             `json:"createdDate"`
+}
+
+type PostRevisionDiff struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Diff string `json:"diff"`
+}
+
+func SerializeOnePostRevision(revision models.PostRevision) PostRevision {
+	return PostRevision{
+		Number:       revision.Number,
+		UserID:       revision.UserID,
+		Title:        revision.Title,
+		Body:         revision.Body,
+		RestoredFrom: revision.RestoredFrom,
+		CreatedDate:  revision.CreatedDate.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func SerializeManyPostRevisions(revisions []models.PostRevision) []PostRevision {
+	revisionsArray := []PostRevision{}
+
+	for _, revision := range revisions {
+		revisionsArray = append(revisionsArray, SerializeOnePostRevision(revision))
+	}
+
+	return revisionsArray
+}
@@ -7,16 +7,26 @@ import (
 )
 
 type Role struct {
-	ID          primitive.ObjectID `json:"_id,omitempty"`
-	Name        string             `json:"name"`
-	Permissions []string           `json:"permissions"`
+	ID          primitive.ObjectID   `json:"_id,omitempty"`
+	Name        string               `json:"name"`
+	Permissions []string             `json:"permissions"`
+	RequireMFA  bool                 `json:"requireMfa"`
+	ParentIDs   []primitive.ObjectID `json:"_parentIds"`
 }
 
 func SerializeOneRole(role models.Role) Role {
+	parentIds := role.ParentIDs
+
+	if parentIds == nil {
+		parentIds = []primitive.ObjectID{}
+	}
+
 	return Role{
 		ID:          role.ID,
 		Name:        role.Name,
 		Permissions: role.Permissions,
+		RequireMFA:  role.RequireMFA,
+		ParentIDs:   parentIds,
 	}
 }
 
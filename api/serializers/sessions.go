@@ -0,0 +1,37 @@
+package serializers
+
+import (
+	"auth_blog_service/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Session struct {
+	ID           primitive.ObjectID `json:"_id,omitempty"`
+	CreatedDate  string             `json:"createdDate,omitempty"`
+	LastSeenDate string             `json:"lastSeenDate,omitempty"`
+	ExpiresDate  string             `json:"expiresDate,omitempty"`
+	IP           string             `json:"ip,omitempty"`
+	UserAgent    string             `json:"userAgent,omitempty"`
+}
+
+func SerializeOneSession(session models.Session) Session {
+	return Session{
+		ID:           session.ID,
+		CreatedDate:  session.CreatedDate.Time.Format("2006-01-02T15:04:05Z07:00"),
+		LastSeenDate: session.LastSeenDate.Time.Format("2006-01-02T15:04:05Z07:00"),
+		ExpiresDate:  session.ExpiresDate.Format("2006-01-02T15:04:05Z07:00"),
+		IP:           session.IP,
+		UserAgent:    session.UserAgent,
+	}
+}
+
+func SerializeManySessions(sessions []models.Session) []Session {
+	var sessionsArray []Session
+
+	for _, session := range sessions {
+		sessionsArray = append(sessionsArray, SerializeOneSession(session))
+	}
+
+	return sessionsArray
+}
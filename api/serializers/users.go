@@ -1,26 +1,48 @@
 package serializers
 
 import (
+	constants "auth_blog_service/constants"
 	"auth_blog_service/models"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
-	ID        primitive.ObjectID `json:"_id,omitempty"`
-	RoleID    primitive.ObjectID `json:"_roleId"`
-	Name      string             `json:"name"`
-	UserName  string             `json:"username"`
-	BirthDate string             `json:"birthDate"`
+	ID         primitive.ObjectID   `json:"_id,omitempty"`
+	RoleID     primitive.ObjectID   `json:"_roleId"`
+	RoleIDs    []primitive.ObjectID `json:"_roleIds"`
+	Name       string               `json:"name"`
+	UserName   string               `json:"username"`
+	Email      string               `json:"email,omitempty"`
+	BirthDate  string               `json:"birthDate"`
+	MFAEnabled bool                 `json:"mfaEnabled"`
+	Status     string               `json:"status"`
 }
 
 func SerializeOneUser(user models.User) User {
+	status := user.Status
+
+	// Users created before registration existed have no status and are active.
+	if status == "" {
+		status = constants.UserActive
+	}
+
+	roleIds := user.RoleIDs
+
+	if len(roleIds) == 0 {
+		roleIds = []primitive.ObjectID{user.RoleID}
+	}
+
 	return User{
-		ID:        user.ID,
-		RoleID:    user.RoleID,
-		Name:      user.Name,
-		UserName:  user.UserName,
-		BirthDate: user.BirthDate.Time.Format("2006-01-02"),
+		ID:         user.ID,
+		RoleID:     user.RoleID,
+		RoleIDs:    roleIds,
+		Name:       user.Name,
+		UserName:   user.UserName,
+		Email:      user.Email,
+		BirthDate:  user.BirthDate.Time.Format("2006-01-02"),
+		MFAEnabled: user.MFAEnabled,
+		Status:     status,
 	}
 }
 
@@ -0,0 +1,7 @@
+package types
+
+type APIKeyBody struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+	ExpiresDate Datetime `json:"expiresDate"`
+}
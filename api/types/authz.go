@@ -0,0 +1,16 @@
+package types
+
+type AuthzCheckBody struct {
+	UserID      string                 `json:"userId"`
+	UserName    string                 `json:"username"`
+	Action      string                 `json:"action"`
+	Resource    map[string]interface{} `json:"resource"`
+	Environment map[string]interface{} `json:"environment"`
+}
+
+type AuthzDecision struct {
+	Allowed bool   `json:"allowed"`
+	Effect  string `json:"effect"`
+	Rule    string `json:"rule,omitempty"`
+	Reason  string `json:"reason"`
+}
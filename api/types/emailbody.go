@@ -0,0 +1,5 @@
+package types
+
+type EmailBody struct {
+	Email string `json:"email"`
+}
@@ -0,0 +1,22 @@
+package types
+
+type Introspection struct {
+	Active      bool     `json:"active"`
+	Subject     string   `json:"sub,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	TokenType   string   `json:"token_type,omitempty"`
+	Role        string   `json:"role,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	IssuedAt    int64    `json:"iat,omitempty"`
+	Expiration  int64    `json:"exp,omitempty"`
+	Actor       *Actor   `json:"act,omitempty"`
+}
+
+// Actor is the user acting on behalf of the subject, as in RFC 8693.
+type Actor struct {
+	Subject  string `json:"sub"`
+	Username string `json:"username,omitempty"`
+}
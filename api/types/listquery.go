@@ -0,0 +1,29 @@
+package types
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ListQuery holds the paging, sorting and filtering parameters of a list
+// endpoint. Each list only applies the filters that make sense for it.
+type ListQuery struct {
+	Limit  int64
+	Cursor string
+	Sort   string
+
+	Author      primitive.ObjectID
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Title       string
+	UserName    string
+	Name        string
+}
+
+// Page describes where a list slice stands in the whole list. Next is empty
+// on the last page.
+type Page struct {
+	Next  string
+	Total int64
+}
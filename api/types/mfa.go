@@ -0,0 +1,23 @@
+package types
+
+type MFABody struct {
+	MFAToken     string `json:"mfaToken"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+type MFAChallenge struct {
+	MFARequired        bool   `json:"mfaRequired"`
+	EnrollmentRequired bool   `json:"enrollmentRequired"`
+	MFAToken           string `json:"mfaToken"`
+}
+
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type MFAActivation struct {
+	RecoveryCodes []string   `json:"recoveryCodes"`
+	Tokens        *TokenPair `json:"tokens,omitempty"`
+}
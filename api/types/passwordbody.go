@@ -0,0 +1,11 @@
+package types
+
+type ForgotPasswordBody struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type ResetPasswordBody struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
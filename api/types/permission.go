@@ -0,0 +1,6 @@
+package types
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
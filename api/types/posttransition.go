@@ -0,0 +1,9 @@
+package types
+
+// PostTransition moves a post from any of the From statuses to To. Callers
+// need Permission on the post.
+type PostTransition struct {
+	From       []string
+	To         string
+	Permission string
+}
@@ -0,0 +1,13 @@
+package types
+
+type ProfileBody struct {
+	Name      string   `json:"name"`
+	UserName  string   `json:"username"`
+	Email     string   `json:"email"`
+	BirthDate Datetime `json:"birthDate"`
+}
+
+type ChangePasswordBody struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
@@ -0,0 +1,5 @@
+package types
+
+type RefreshBody struct {
+	RefreshToken string `json:"refreshToken"`
+}
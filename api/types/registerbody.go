@@ -0,0 +1,9 @@
+package types
+
+type RegisterBody struct {
+	Name      string   `json:"name"`
+	UserName  string   `json:"username"`
+	Email     string   `json:"email"`
+	Password  string   `json:"password"`
+	BirthDate Datetime `json:"birthDate"`
+}
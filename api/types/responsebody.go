@@ -4,4 +4,6 @@ type ResponseBody struct {
 	Status  int         `json:"status,omitempty"`
 	Message string      `json:"message,omitempty"`
 	Result  interface{} `json:"result,omitempty"`
+	Next    string      `json:"next,omitempty"`
+	Total   *int64      `json:"total,omitempty"`
 }
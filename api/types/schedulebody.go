@@ -0,0 +1,9 @@
+package types
+
+import "time"
+
+// ScheduleBody takes publishAt as an RFC 3339 time, e.g.
+// "2021-09-06T09:00:00+01:00".
+type ScheduleBody struct {
+	PublishAt time.Time `json:"publishAt"`
+}
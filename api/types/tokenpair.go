@@ -0,0 +1,10 @@
+package types
+
+type TokenPair struct {
+	AccessToken  string `json:"accessToken"`
+	RefreshToken string `json:"refreshToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"idToken,omitempty"`
+}